@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
+)
+
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+
+	apiKeyPrefix = "usk_"
+)
+
+var ErrInvalidCredentials = apperrors.New(
+	apperrors.KindUnauthenticated,
+	"invalid_credentials",
+	"invalid credentials",
+)
+
+// roleRanks orders the roles, a role is granted everything a lower ranked role
+// is granted.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// Principal is the authenticated caller of a request, urls created by it are
+// owned by OwnerID.
+type Principal struct {
+	OwnerID uuid.UUID
+	Role    string
+}
+
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// HasRole reports whether the role of p is role or ranked above it.
+func (p Principal) HasRole(role string) bool {
+	rank, ok := roleRanks[p.Role]
+	return ok && rank >= roleRanks[role]
+}
+
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HighestRole returns the highest ranked known role of roles, unknown roles
+// are ignored.
+func HighestRole(roles []string) (string, bool) {
+	highest := ""
+	for _, role := range roles {
+		if roleRanks[role] > roleRanks[highest] {
+			highest = role
+		}
+	}
+	return highest, highest != ""
+}
+
+type principal struct{}
+
+func PrincipalFromContext(c context.Context) (Principal, bool) {
+	p, ok := c.Value(principal{}).(Principal)
+	return p, ok
+}
+
+func AttachPrincipalToContext(c context.Context, p Principal) context.Context {
+	return context.WithValue(c, principal{}, p)
+}
+
+// GenerateApiKey returns a new random api key, only its HashApiKey is stored.
+func GenerateApiKey() (string, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", fmt.Errorf("failed generating api key with error=%w", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// HashApiKey hashes key with sha256, api keys carry enough entropy that a slow
+// password hash is not needed.
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+type ApiKeyAuthenticator interface {
+	Authenticate(c context.Context, key string) (Principal, error)
+}
+
+// Authenticator verifies bearer tokens shaped like a JWT with the JWTVerifier
+// and resolves every other token as an api key.
+type Authenticator struct {
+	apiKeys  ApiKeyAuthenticator
+	verifier *JWTVerifier
+}
+
+// NewAuthenticator returns an Authenticator, verifier is nil when JWTs are
+// not accepted.
+func NewAuthenticator(apiKeys ApiKeyAuthenticator, verifier *JWTVerifier) *Authenticator {
+	return &Authenticator{apiKeys: apiKeys, verifier: verifier}
+}
+
+func (a *Authenticator) Authenticate(c context.Context, token string) (Principal, error) {
+	if a.verifier != nil && isJWT(token) {
+		return a.verifier.Verify(token)
+	}
+	return a.apiKeys.Authenticate(c, token)
+}
+
+func isJWT(token string) bool {
+	return !strings.HasPrefix(token, apiKeyPrefix) && strings.Count(token, ".") == 2
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/log"
+)
+
+const maxJWKSSize = 1 << 20
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JWKS holds the public keys of a JSON Web Key Set read from a local file or
+// fetched from an http(s) url, keys are looked up by their kid.
+type JWKS struct {
+	source string
+	client *http.Client
+
+	mutex sync.RWMutex
+	keys  map[string]crypto.PublicKey
+}
+
+func NewJWKS(source string) *JWKS {
+	return &JWKS{
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   map[string]crypto.PublicKey{},
+	}
+}
+
+// Load reads the key set from the source and replaces the current keys.
+func (j *JWKS) Load(c context.Context) error {
+	data, err := j.read(c)
+	if err != nil {
+		return fmt.Errorf("failed reading jwks from source=%s with error=%w", j.source, err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("failed parsing jwks from source=%s with error=%w", j.source, err)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.keys = keys
+	return nil
+}
+
+// Refresh reloads the key set every interval until c is done, a failed
+// reload keeps the previous keys.
+func (j *JWKS) Refresh(c context.Context, interval time.Duration) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "JWKS Refresh").Logger()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+			logger.Info().Msgf("refreshing jwks from source=%s", j.source)
+			err := j.Load(c)
+			if err != nil {
+				logger.Error().Err(err).Msg(err.Error())
+				continue
+			}
+			logger.Info().Msgf("refreshed jwks from source=%s", j.source)
+		}
+	}
+}
+
+// Key returns the key identified by kid, a token without kid is accepted
+// when the set only has one key.
+func (j *JWKS) Key(kid string) (crypto.PublicKey, bool) {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKS) read(c context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected statusCode=%d", res.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, maxJWKSSize))
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	set := jsonWebKeySet{}
+	err := json.Unmarshal(data, &set)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		switch jwk.Kty {
+		case "RSA":
+			key, err = parseRSAKey(jwk)
+		case "EC":
+			key, err = parseECKey(jwk)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing kid=%s with error=%w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks has no RSA or EC signing key")
+	}
+	return keys, nil
+}
+
+func parseRSAKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding modulus with error=%w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding exponent with error=%w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid RSA public key")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func parseECKey(jwk jsonWebKey) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	var validator ecdh.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve, validator = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, validator = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, validator = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve=%s", jwk.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding x with error=%w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding y with error=%w", err)
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("invalid EC public key coordinates for curve=%s", jwk.Crv)
+	}
+	uncompressed := append([]byte{4}, append(x, y...)...)
+	_, err = validator.NewPublicKey(uncompressed)
+	if err != nil {
+		return nil, fmt.Errorf("EC public key is not on curve=%s with error=%w", jwk.Crv, err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
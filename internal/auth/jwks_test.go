@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParseJWKS(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed generating ec key with error=%s", err)
+	}
+	ecJWK := jsonWebKey{
+		Kty: "EC",
+		Kid: testECKid,
+		Crv: "P-256",
+		X:   encodeCoordinate(ecKey.X, 32),
+		Y:   encodeCoordinate(ecKey.Y, 32),
+	}
+	offCurve := ecJWK
+	offCurve.Y = encodeCoordinate(new(big.Int).Add(ecKey.Y, big.NewInt(1)), 32)
+	unknownCurve := ecJWK
+	unknownCurve.Crv = "secp256k1"
+	encryption := ecJWK
+	encryption.Use = "enc"
+	weakExponent := jsonWebKey{
+		Kty: "RSA",
+		Kid: testRSAKid,
+		N:   encodeBigInt(big.NewInt(3233)),
+		E:   encodeBigInt(big.NewInt(1)),
+	}
+
+	tests := []struct {
+		name    string
+		keys    []jsonWebKey
+		kids    []string
+		wantErr bool
+	}{
+		{name: "EC signing key", keys: []jsonWebKey{ecJWK}, kids: []string{testECKid}},
+		{
+			name: "encryption and unsupported keys are skipped",
+			keys: []jsonWebKey{encryption, {Kty: "oct", Kid: "hmac"}, ecJWK},
+			kids: []string{testECKid},
+		},
+		{name: "only encryption keys", keys: []jsonWebKey{encryption}, wantErr: true},
+		{name: "point off the curve", keys: []jsonWebKey{offCurve}, wantErr: true},
+		{name: "unknown curve", keys: []jsonWebKey{unknownCurve}, wantErr: true},
+		{name: "RSA exponent below 3", keys: []jsonWebKey{weakExponent}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(jsonWebKeySet{Keys: test.keys})
+			if err != nil {
+				t.Fatalf("failed marshalling jwks with error=%s", err)
+			}
+
+			keys, err := parseJWKS(data)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error got keys=%v", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected kids=%v got error=%s", test.kids, err)
+			}
+			if len(keys) != len(test.kids) {
+				t.Fatalf("expected kids=%v got keys=%v", test.kids, keys)
+			}
+			for _, kid := range test.kids {
+				if _, ok := keys[kid]; !ok {
+					t.Fatalf("expected kid=%s in keys=%v", kid, keys)
+				}
+			}
+		})
+	}
+}
+
+func TestJWKSKey(t *testing.T) {
+	keys := newTestKeys(t)
+	if _, ok := keys.jwks.Key(testRSAKid); !ok {
+		t.Fatalf("expected kid=%s to be found", testRSAKid)
+	}
+	if _, ok := keys.jwks.Key(""); ok {
+		t.Fatal("expected a token without kid to be rejected by a set of several keys")
+	}
+
+	single := NewJWKS("")
+	single.keys = map[string]crypto.PublicKey{testECKid: &keys.ec.PublicKey}
+	if _, ok := single.Key(""); !ok {
+		t.Fatal("expected a token without kid to use the only key of the set")
+	}
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/Alturino/url-shortener/internal/config"
+)
+
+const (
+	defaultOwnerClaim = "sub"
+	defaultRolesClaim = "roles"
+)
+
+// JWTVerifier validates RS256 and ES256 tokens against a JWKS and maps their
+// claims to a Principal.
+type JWTVerifier struct {
+	jwks   *JWKS
+	parser *jwt.Parser
+	config config.JWT
+}
+
+func NewJWTVerifier(jwks *JWKS, config config.JWT) *JWTVerifier {
+	if config.OwnerClaim == "" {
+		config.OwnerClaim = defaultOwnerClaim
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = defaultRolesClaim
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	return &JWTVerifier{jwks: jwks, parser: jwt.NewParser(options...), config: config}
+}
+
+// Verify checks the signature, issuer, audience and expiry of token. The owner
+// claim is used as the owner id when it is a uuid and is otherwise hashed
+// together with the issuer into a stable one, tokens without a known role get
+// the configured default role.
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := v.jwks.Key(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown kid=%s", kid)
+		}
+		return key, nil
+	})
+	if err != nil {
+		return Principal{}, fmt.Errorf(
+			"failed verifying jwt with error=%w: %w",
+			ErrInvalidCredentials,
+			err,
+		)
+	}
+
+	subject, _ := lookupClaim(claims, v.config.OwnerClaim).(string)
+	if subject == "" {
+		return Principal{}, fmt.Errorf(
+			"jwt has no %s claim with error=%w",
+			v.config.OwnerClaim,
+			ErrInvalidCredentials,
+		)
+	}
+	ownerID, err := uuid.Parse(subject)
+	if err != nil {
+		issuer, _ := claims.GetIssuer()
+		ownerID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(issuer+"#"+subject))
+	}
+
+	role, ok := HighestRole(claimStrings(lookupClaim(claims, v.config.RolesClaim)))
+	if !ok {
+		role = v.config.DefaultRole
+	}
+	if !IsValidRole(role) {
+		return Principal{}, fmt.Errorf(
+			"jwt of subject=%s has no known role with error=%w",
+			subject,
+			ErrInvalidCredentials,
+		)
+	}
+
+	return Principal{OwnerID: ownerID, Role: role}, nil
+}
+
+// lookupClaim resolves a dotted path such as realm_access.roles in claims.
+func lookupClaim(claims jwt.MapClaims, path string) interface{} {
+	var value interface{} = map[string]interface{}(claims)
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+// claimStrings accepts a claim that is either a list of strings or a space
+// separated string.
+func claimStrings(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,388 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/Alturino/url-shortener/internal/config"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com/"
+	testAudience = "url-shortener"
+	testRSAKid   = "rsa"
+	testECKid    = "ec"
+)
+
+type testKeys struct {
+	rsa  *rsa.PrivateKey
+	ec   *ecdsa.PrivateKey
+	jwks *JWKS
+}
+
+// newTestKeys generates an RSA and a P-256 key and loads their public halves
+// through a JWKS file.
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed generating rsa key with error=%s", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed generating ec key with error=%s", err)
+	}
+
+	set := jsonWebKeySet{Keys: []jsonWebKey{
+		{
+			Kty: "RSA",
+			Kid: testRSAKid,
+			Use: "sig",
+			N:   encodeBigInt(rsaKey.N),
+			E:   encodeBigInt(big.NewInt(int64(rsaKey.E))),
+		},
+		{
+			Kty: "EC",
+			Kid: testECKid,
+			Crv: "P-256",
+			X:   encodeCoordinate(ecKey.X, 32),
+			Y:   encodeCoordinate(ecKey.Y, 32),
+		},
+	}}
+	jwks := loadTestJWKS(t, set)
+
+	return testKeys{rsa: rsaKey, ec: ecKey, jwks: jwks}
+}
+
+func loadTestJWKS(t *testing.T, set jsonWebKeySet) *JWKS {
+	t.Helper()
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed marshalling jwks with error=%s", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		t.Fatalf("failed writing jwks with error=%s", err)
+	}
+
+	jwks := NewJWKS(path)
+	err = jwks.Load(context.Background())
+	if err != nil {
+		t.Fatalf("failed loading jwks with error=%s", err)
+	}
+	return jwks
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func encodeCoordinate(n *big.Int, size int) string {
+	return base64.RawURLEncoding.EncodeToString(n.FillBytes(make([]byte, size)))
+}
+
+func sign(
+	t *testing.T,
+	method jwt.SigningMethod,
+	kid string,
+	key interface{},
+	claims jwt.MapClaims,
+) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed signing %s token with error=%s", method.Alg(), err)
+	}
+	return signed
+}
+
+func validClaims(subject string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   subject,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"roles": []string{RoleEditor},
+	}
+}
+
+func with(claims jwt.MapClaims, changes map[string]interface{}) jwt.MapClaims {
+	for name, value := range changes {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+func TestJWTVerifierVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := NewJWTVerifier(keys.jwks, config.JWT{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+	subject := uuid.New()
+	ownerID := subject
+	valid := func() jwt.MapClaims { return validClaims(subject.String()) }
+
+	tests := []struct {
+		name      string
+		token     string
+		principal Principal
+		wantErr   bool
+	}{
+		{
+			name:      "valid RS256",
+			token:     sign(t, jwt.SigningMethodRS256, testRSAKid, keys.rsa, valid()),
+			principal: Principal{OwnerID: ownerID, Role: RoleEditor},
+		},
+		{
+			name:      "valid ES256",
+			token:     sign(t, jwt.SigningMethodES256, testECKid, keys.ec, valid()),
+			principal: Principal{OwnerID: ownerID, Role: RoleEditor},
+		},
+		{
+			name: "wrong issuer",
+			token: sign(t, jwt.SigningMethodRS256, testRSAKid, keys.rsa, with(
+				valid(),
+				map[string]interface{}{"iss": "https://other.example.com/"},
+			)),
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			token: sign(t, jwt.SigningMethodES256, testECKid, keys.ec, with(
+				valid(),
+				map[string]interface{}{"aud": "other"},
+			)),
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			token: sign(t, jwt.SigningMethodRS256, testRSAKid, keys.rsa, with(
+				valid(),
+				map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()},
+			)),
+			wantErr: true,
+		},
+		{
+			name: "missing exp",
+			token: sign(t, jwt.SigningMethodES256, testECKid, keys.ec, with(
+				valid(),
+				map[string]interface{}{"exp": nil},
+			)),
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			token:   sign(t, jwt.SigningMethodRS256, "unknown", keys.rsa, valid()),
+			wantErr: true,
+		},
+		{
+			name:    "missing kid with several keys",
+			token:   sign(t, jwt.SigningMethodRS256, "", keys.rsa, valid()),
+			wantErr: true,
+		},
+		{
+			name:    "ES256 signed token with the kid of the RSA key",
+			token:   sign(t, jwt.SigningMethodES256, testRSAKid, keys.ec, valid()),
+			wantErr: true,
+		},
+		{
+			name: "HS256 signed with the RSA public key",
+			token: sign(
+				t,
+				jwt.SigningMethodHS256,
+				testRSAKid,
+				keys.rsa.PublicKey.N.Bytes(),
+				valid(),
+			),
+			wantErr: true,
+		},
+		{
+			name: "none",
+			token: sign(
+				t,
+				jwt.SigningMethodNone,
+				testRSAKid,
+				jwt.UnsafeAllowNoneSignatureType,
+				valid(),
+			),
+			wantErr: true,
+		},
+		{
+			name: "missing sub",
+			token: sign(t, jwt.SigningMethodRS256, testRSAKid, keys.rsa, with(
+				valid(),
+				map[string]interface{}{"sub": nil},
+			)),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			principal, err := verifier.Verify(test.token)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf(
+						"expected error=%s got principal=%+v error=%v",
+						ErrInvalidCredentials,
+						principal,
+						err,
+					)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected principal=%+v got error=%s", test.principal, err)
+			}
+			if principal != test.principal {
+				t.Fatalf("expected principal=%+v got principal=%+v", test.principal, principal)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierVerifyRoles(t *testing.T) {
+	keys := newTestKeys(t)
+	subject := uuid.New()
+
+	tests := []struct {
+		name    string
+		config  config.JWT
+		claims  map[string]interface{}
+		role    string
+		wantErr bool
+	}{
+		{
+			name:   "highest of a list",
+			claims: map[string]interface{}{"roles": []string{RoleViewer, RoleAdmin, RoleEditor}},
+			role:   RoleAdmin,
+		},
+		{
+			name:   "space separated string",
+			claims: map[string]interface{}{"roles": "viewer editor"},
+			role:   RoleEditor,
+		},
+		{
+			name:   "unknown roles are ignored",
+			claims: map[string]interface{}{"roles": []string{"owner", RoleViewer}},
+			role:   RoleViewer,
+		},
+		{
+			name:   "nested roles claim",
+			config: config.JWT{RolesClaim: "realm_access.roles"},
+			claims: map[string]interface{}{
+				"roles":        nil,
+				"realm_access": map[string]interface{}{"roles": []string{RoleAdmin}},
+			},
+			role: RoleAdmin,
+		},
+		{
+			name:   "default role without roles claim",
+			config: config.JWT{DefaultRole: RoleViewer},
+			claims: map[string]interface{}{"roles": nil},
+			role:   RoleViewer,
+		},
+		{
+			name:   "default role without known role",
+			config: config.JWT{DefaultRole: RoleEditor},
+			claims: map[string]interface{}{"roles": []string{"owner"}},
+			role:   RoleEditor,
+		},
+		{
+			name:    "no known role and no default role",
+			claims:  map[string]interface{}{"roles": []string{"owner"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown default role",
+			config:  config.JWT{DefaultRole: "owner"},
+			claims:  map[string]interface{}{"roles": nil},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier := NewJWTVerifier(keys.jwks, test.config)
+			token := sign(
+				t,
+				jwt.SigningMethodES256,
+				testECKid,
+				keys.ec,
+				with(validClaims(subject.String()), test.claims),
+			)
+
+			principal, err := verifier.Verify(token)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf(
+						"expected error=%s got principal=%+v error=%v",
+						ErrInvalidCredentials,
+						principal,
+						err,
+					)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected role=%s got error=%s", test.role, err)
+			}
+			if principal.Role != test.role {
+				t.Fatalf("expected role=%s got role=%s", test.role, principal.Role)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierVerifyOwnerID(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := NewJWTVerifier(keys.jwks, config.JWT{})
+
+	token := sign(t, jwt.SigningMethodRS256, testRSAKid, keys.rsa, validClaims("auth0|42"))
+	first, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("failed verifying token with error=%s", err)
+	}
+	token = sign(t, jwt.SigningMethodES256, testECKid, keys.ec, validClaims("auth0|42"))
+	second, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("failed verifying token with error=%s", err)
+	}
+
+	expected := uuid.NewSHA1(uuid.NameSpaceURL, []byte(testIssuer+"#auth0|42"))
+	if first.OwnerID != expected || second.OwnerID != expected {
+		t.Fatalf(
+			"expected ownerID=%s got ownerID=%s and ownerID=%s",
+			expected,
+			first.OwnerID,
+			second.OwnerID,
+		)
+	}
+}
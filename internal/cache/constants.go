@@ -1,5 +1,9 @@
 package cache
 
 const (
-	KeyUrl = "url:%s"
+	KeyUrl                 = "url:%s"
+	KeyUrlNotFound         = "url_not_found:%s"
+	KeyInvalidationChannel = "url_invalidation"
+	KeyVisitors            = "visitors:%s:%s"
+	KeyRateLimit           = "rate_limit:%s:%d"
 )
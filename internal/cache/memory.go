@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const defaultMemoryCapacity = 10000
+
+type memoryEntry struct {
+	key       string
+	url       repository.Url
+	expiresAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// MemoryCache is an in-process LRU bounded by capacity, entries are evicted
+// when the capacity is reached or their ttl is over.
+type MemoryCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List
+	options  Options
+}
+
+func NewMemoryCache(capacity int, options Options) *MemoryCache {
+	if capacity <= 0 {
+		capacity = defaultMemoryCapacity
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		lru:      list.New(),
+		options:  options,
+	}
+}
+
+func (m *MemoryCache) Get(c context.Context, shortUrl string) (repository.Url, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, ok := m.get(KeyUrl, shortUrl)
+	if !ok {
+		return repository.Url{}, ErrCacheMiss
+	}
+	if m.options.RefreshTTLOnAccess {
+		entry.expiresAt = expiresAt(m.options.UrlTTL)
+	}
+	return entry.url, nil
+}
+
+func (m *MemoryCache) Set(c context.Context, url repository.Url) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.remove(KeyUrlNotFound, url.ShortUrl)
+	m.put(&memoryEntry{
+		key:       memoryKey(KeyUrl, url.ShortUrl),
+		url:       url,
+		expiresAt: expiresAt(m.options.UrlTTL),
+	})
+	return nil
+}
+
+func (m *MemoryCache) SetMany(c context.Context, urls []repository.Url) error {
+	for _, url := range urls {
+		_ = m.Set(c, url)
+	}
+	return nil
+}
+
+func (m *MemoryCache) Fill(c context.Context, url repository.Url) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.get(KeyUrl, url.ShortUrl); ok {
+		return nil
+	}
+	m.put(&memoryEntry{
+		key:       memoryKey(KeyUrl, url.ShortUrl),
+		url:       url,
+		expiresAt: expiresAt(m.options.UrlTTL),
+	})
+	return nil
+}
+
+func (m *MemoryCache) Delete(c context.Context, shortUrl string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.remove(KeyUrl, shortUrl)
+	return nil
+}
+
+// Invalidate drops both the url and the negative entry of shortUrl.
+func (m *MemoryCache) Invalidate(shortUrl string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.remove(KeyUrl, shortUrl)
+	m.remove(KeyUrlNotFound, shortUrl)
+}
+
+func (m *MemoryCache) IncrementVisitedCount(
+	c context.Context,
+	shortUrl string,
+	delta int64,
+) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, ok := m.get(KeyUrl, shortUrl)
+	if !ok {
+		return 0, ErrCacheMiss
+	}
+	entry.url.VisitedCount += int32(delta)
+	return int64(entry.url.VisitedCount), nil
+}
+
+func (m *MemoryCache) IsNotFound(c context.Context, shortUrl string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, ok := m.get(KeyUrlNotFound, shortUrl)
+	return ok, nil
+}
+
+func (m *MemoryCache) SetNotFound(c context.Context, shortUrl string) error {
+	if m.options.NotFoundTTL <= 0 {
+		return nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.put(&memoryEntry{
+		key:       memoryKey(KeyUrlNotFound, shortUrl),
+		expiresAt: expiresAt(m.options.NotFoundTTL),
+	})
+	return nil
+}
+
+func (m *MemoryCache) get(format string, shortUrl string) (*memoryEntry, bool) {
+	element, ok := m.entries[memoryKey(format, shortUrl)]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if entry.expired(time.Now()) {
+		m.lru.Remove(element)
+		delete(m.entries, entry.key)
+		return nil, false
+	}
+	m.lru.MoveToFront(element)
+	return entry, true
+}
+
+func (m *MemoryCache) put(entry *memoryEntry) {
+	if element, ok := m.entries[entry.key]; ok {
+		element.Value = entry
+		m.lru.MoveToFront(element)
+		return
+	}
+
+	m.entries[entry.key] = m.lru.PushFront(entry)
+	for m.lru.Len() > m.capacity {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+func (m *MemoryCache) remove(format string, shortUrl string) {
+	key := memoryKey(format, shortUrl)
+	if element, ok := m.entries[key]; ok {
+		m.lru.Remove(element)
+		delete(m.entries, key)
+	}
+}
+
+func memoryKey(format string, shortUrl string) string {
+	return fmt.Sprintf(format, shortUrl)
+}
+
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func isNotFound(
+	c context.Context,
+	client *redis.Client,
+	options Options,
+	shortUrl string,
+) (bool, error) {
+	if options.NotFoundTTL <= 0 {
+		return false, nil
+	}
+	exists, err := client.Exists(c, fmt.Sprintf(KeyUrlNotFound, shortUrl)).Result()
+	if err != nil {
+		return false, err
+	}
+	return exists > 0, nil
+}
+
+func setNotFound(
+	c context.Context,
+	client *redis.Client,
+	options Options,
+	shortUrl string,
+) error {
+	if options.NotFoundTTL <= 0 {
+		return nil
+	}
+	return client.Set(c, fmt.Sprintf(KeyUrlNotFound, shortUrl), 1, options.NotFoundTTL).Err()
+}
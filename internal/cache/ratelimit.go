@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript counts a request in the current fixed window unless the
+// sliding window estimate, the previous window weighted by how much of it
+// still overlaps plus the current window, already reached the limit.
+// It returns whether the request is allowed and both window counts.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if previous * (window - elapsed) / window + current >= limit then
+	return {0, current, previous}
+end
+current = redis.call('INCR', KEYS[1])
+if current == 1 then
+	redis.call('PEXPIRE', KEYS[1], window * 2)
+end
+return {1, current, previous}
+`)
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimiter is a sliding window counter shared by every replica through
+// redis, it keeps two counters per key instead of a log of every request.
+type RateLimiter struct {
+	client *redis.Client
+}
+
+func NewRateLimiter(client *redis.Client) *RateLimiter {
+	return &RateLimiter{client: client}
+}
+
+func (r *RateLimiter) Allow(
+	c context.Context,
+	key string,
+	limit int64,
+	window time.Duration,
+) (RateLimitResult, error) {
+	windowMs := window.Milliseconds()
+	nowMs := time.Now().UnixMilli()
+	index := nowMs / windowMs
+	elapsed := nowMs % windowMs
+
+	counts, err := slidingWindowScript.Run(
+		c,
+		r.client,
+		[]string{fmt.Sprintf(KeyRateLimit, key, index), fmt.Sprintf(KeyRateLimit, key, index-1)},
+		limit,
+		windowMs,
+		elapsed,
+	).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	allowed, current, previous := counts[0] == 1, counts[1], counts[2]
+
+	weight := float64(windowMs-elapsed) / float64(windowMs)
+	used := int64(float64(previous)*weight) + current
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(limit-used, 0),
+		Reset:     time.Duration(windowMs-elapsed) * time.Millisecond,
+	}
+	if allowed {
+		return result, nil
+	}
+
+	// the estimate drops below the limit once enough of the previous window
+	// slid out, or at the next window when the current one is already full
+	retryAfter := windowMs - elapsed
+	if current < limit && previous > 0 {
+		retryAfter -= (limit - current) * windowMs / previous
+	}
+	result.RetryAfter = time.Duration(max(retryAfter, 1)) * time.Millisecond
+	return result, nil
+}
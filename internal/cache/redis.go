@@ -28,23 +28,28 @@ func NewCacheClient(
 	})
 	logger.Info().Msg("initialized redis client")
 
-	logger.Info().Msg("pinging redis client")
-	err := redisClient.Ping(c).Err()
-	if err != nil {
-		err = fmt.Errorf("failed pinging redis client with error=%w", err)
-		logger.Fatal().Err(err).Msg(err.Error())
-	}
-	logger.Info().Msg("pinged redis client")
-
 	logger.Info().Msg("attach instrumentation to redis client")
-	err = redisotel.InstrumentTracing(redisClient, redisotel.WithAttributes(semconv.DBSystemRedis))
+	err := redisotel.InstrumentTracing(redisClient, redisotel.WithAttributes(semconv.DBSystemRedis))
 	if err != nil {
 		err = fmt.Errorf("failed attaching instrumentation to redis client with error=%w", err)
 		logger.Fatal().Err(err).Msg(err.Error())
 	}
 	logger.Info().Msg("attach instrumentation to redis client")
 
-	logger.Info().Msg("successed connecting to redis")
-
 	return redisClient
 }
+
+// Ping checks that redis is reachable, the client keeps reconnecting on its
+// own so features that degrade without redis can start while it is down.
+func Ping(c context.Context, client *redis.Client) error {
+	err := client.Ping(c).Err()
+	if err != nil {
+		return fmt.Errorf("failed pinging redis client with error=%w", err)
+	}
+	return nil
+}
+
+// UsesRedis reports whether the url cache backend is stored in redis.
+func UsesRedis(backend string) bool {
+	return backend != BackendMemory
+}
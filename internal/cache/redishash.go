@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const (
+	fieldData         = "data"
+	fieldVisitedCount = "visited_count"
+)
+
+var (
+	// fillScript writes the hash only when the key does not exist yet.
+	fillScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'data', ARGV[1], 'visited_count', ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+	// incrementScript increments visited_count only when the key exists so a
+	// partial hash is never created.
+	incrementScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+return redis.call('HINCRBY', KEYS[1], 'visited_count', ARGV[1])
+`)
+)
+
+// RedisCache stores urls as plain redis hashes so it works against any redis
+// compatible server such as valkey. The url is kept as json in the data field
+// and visited_count in its own field so it can be incremented atomically.
+type RedisCache struct {
+	client  *redis.Client
+	options Options
+}
+
+func NewRedisCache(client *redis.Client, options Options) *RedisCache {
+	return &RedisCache{client: client, options: options}
+}
+
+func (r *RedisCache) Get(c context.Context, shortUrl string) (repository.Url, error) {
+	key := fmt.Sprintf(KeyUrl, shortUrl)
+
+	pipe := r.client.Pipeline()
+	get := pipe.HMGet(c, key, fieldData, fieldVisitedCount)
+	if r.options.RefreshTTLOnAccess && r.options.UrlTTL > 0 {
+		pipe.Expire(c, key, r.options.UrlTTL)
+	}
+	_, err := pipe.Exec(c)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return repository.Url{}, err
+	}
+
+	values, err := get.Result()
+	if err != nil {
+		return repository.Url{}, err
+	}
+	data, ok := values[0].(string)
+	if !ok {
+		return repository.Url{}, ErrCacheMiss
+	}
+
+	url := repository.Url{}
+	err = json.Unmarshal([]byte(data), &url)
+	if err != nil {
+		return repository.Url{}, fmt.Errorf(
+			"failed unmarshalling shortUrl=%s from cache with error=%w",
+			shortUrl,
+			err,
+		)
+	}
+	if visitedCount, ok := values[1].(string); ok {
+		count, err := strconv.ParseInt(visitedCount, 10, 32)
+		if err == nil {
+			url.VisitedCount = int32(count)
+		}
+	}
+	return url, nil
+}
+
+func (r *RedisCache) Set(c context.Context, url repository.Url) error {
+	_, err := r.client.TxPipelined(c, func(pipe redis.Pipeliner) error {
+		return r.set(c, pipe, url)
+	})
+	return err
+}
+
+func (r *RedisCache) SetMany(c context.Context, urls []repository.Url) error {
+	_, err := r.client.Pipelined(c, func(pipe redis.Pipeliner) error {
+		for _, url := range urls {
+			err := r.set(c, pipe, url)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *RedisCache) set(c context.Context, pipe redis.Pipeliner, url repository.Url) error {
+	data, err := json.Marshal(url)
+	if err != nil {
+		return fmt.Errorf("failed marshalling shortUrl=%s with error=%w", url.ShortUrl, err)
+	}
+
+	key := fmt.Sprintf(KeyUrl, url.ShortUrl)
+	pipe.HSet(c, key, fieldData, data, fieldVisitedCount, url.VisitedCount)
+	if r.options.UrlTTL > 0 {
+		pipe.Expire(c, key, r.options.UrlTTL)
+	}
+	pipe.Del(c, fmt.Sprintf(KeyUrlNotFound, url.ShortUrl))
+	return nil
+}
+
+func (r *RedisCache) Fill(c context.Context, url repository.Url) error {
+	data, err := json.Marshal(url)
+	if err != nil {
+		return fmt.Errorf("failed marshalling shortUrl=%s with error=%w", url.ShortUrl, err)
+	}
+
+	key := fmt.Sprintf(KeyUrl, url.ShortUrl)
+	return fillScript.Run(
+		c,
+		r.client,
+		[]string{key},
+		data,
+		url.VisitedCount,
+		r.options.UrlTTL.Milliseconds(),
+	).Err()
+}
+
+func (r *RedisCache) Delete(c context.Context, shortUrl string) error {
+	return r.client.Del(c, fmt.Sprintf(KeyUrl, shortUrl)).Err()
+}
+
+func (r *RedisCache) IncrementVisitedCount(
+	c context.Context,
+	shortUrl string,
+	delta int64,
+) (int64, error) {
+	key := fmt.Sprintf(KeyUrl, shortUrl)
+	incremented, err := incrementScript.Run(c, r.client, []string{key}, delta).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrCacheMiss
+	}
+	return incremented, err
+}
+
+func (r *RedisCache) IsNotFound(c context.Context, shortUrl string) (bool, error) {
+	return isNotFound(c, r.client, r.options, shortUrl)
+}
+
+func (r *RedisCache) SetNotFound(c context.Context, shortUrl string) error {
+	return setNotFound(c, r.client, r.options, shortUrl)
+}
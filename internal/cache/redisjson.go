@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+// jsonIncrementScript increments visited_count only when the key exists so a
+// missing key is reported as a cache miss instead of a module error.
+var jsonIncrementScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+return redis.call('JSON.NUMINCRBY', KEYS[1], '$.visited_count', ARGV[1])
+`)
+
+// RedisJSONCache stores urls as RedisJSON documents, it requires the RedisJSON
+// module to be loaded.
+type RedisJSONCache struct {
+	client  *redis.Client
+	options Options
+}
+
+func NewRedisJSONCache(client *redis.Client, options Options) *RedisJSONCache {
+	return &RedisJSONCache{client: client, options: options}
+}
+
+func (r *RedisJSONCache) Get(c context.Context, shortUrl string) (repository.Url, error) {
+	key := fmt.Sprintf(KeyUrl, shortUrl)
+
+	pipe := r.client.Pipeline()
+	get := pipe.JSONGet(c, key)
+	if r.options.RefreshTTLOnAccess && r.options.UrlTTL > 0 {
+		pipe.Expire(c, key, r.options.UrlTTL)
+	}
+	_, err := pipe.Exec(c)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return repository.Url{}, err
+	}
+
+	jsonCache, err := get.Result()
+	if errors.Is(err, redis.Nil) || (err == nil && jsonCache == "") {
+		return repository.Url{}, ErrCacheMiss
+	}
+	if err != nil {
+		return repository.Url{}, err
+	}
+
+	url := repository.Url{}
+	err = json.Unmarshal([]byte(jsonCache), &url)
+	if err != nil {
+		return repository.Url{}, fmt.Errorf(
+			"failed unmarshalling shortUrl=%s from cache with error=%w",
+			shortUrl,
+			err,
+		)
+	}
+	return url, nil
+}
+
+func (r *RedisJSONCache) Set(c context.Context, url repository.Url) error {
+	_, err := r.client.TxPipelined(c, func(pipe redis.Pipeliner) error {
+		r.set(c, pipe, url)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisJSONCache) SetMany(c context.Context, urls []repository.Url) error {
+	_, err := r.client.Pipelined(c, func(pipe redis.Pipeliner) error {
+		for _, url := range urls {
+			r.set(c, pipe, url)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *RedisJSONCache) set(c context.Context, pipe redis.Pipeliner, url repository.Url) {
+	key := fmt.Sprintf(KeyUrl, url.ShortUrl)
+	pipe.JSONSet(c, key, "$", url)
+	if r.options.UrlTTL > 0 {
+		pipe.Expire(c, key, r.options.UrlTTL)
+	}
+	pipe.Del(c, fmt.Sprintf(KeyUrlNotFound, url.ShortUrl))
+}
+
+func (r *RedisJSONCache) Fill(c context.Context, url repository.Url) error {
+	key := fmt.Sprintf(KeyUrl, url.ShortUrl)
+	_, err := r.client.TxPipelined(c, func(pipe redis.Pipeliner) error {
+		pipe.JSONSetMode(c, key, "$", url, "NX")
+		if r.options.UrlTTL > 0 {
+			pipe.Expire(c, key, r.options.UrlTTL)
+		}
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+func (r *RedisJSONCache) Delete(c context.Context, shortUrl string) error {
+	return r.client.Del(c, fmt.Sprintf(KeyUrl, shortUrl)).Err()
+}
+
+func (r *RedisJSONCache) IncrementVisitedCount(
+	c context.Context,
+	shortUrl string,
+	delta int64,
+) (int64, error) {
+	key := fmt.Sprintf(KeyUrl, shortUrl)
+	incremented, err := jsonIncrementScript.Run(c, r.client, []string{key}, delta).Text()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrCacheMiss
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	visitedCounts := []*int64{}
+	err = json.Unmarshal([]byte(incremented), &visitedCounts)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed parsing incremented visited_count=%s for shortUrl=%s with error=%w",
+			incremented,
+			shortUrl,
+			err,
+		)
+	}
+	if len(visitedCounts) == 0 || visitedCounts[0] == nil {
+		return 0, ErrCacheMiss
+	}
+	return *visitedCounts[0], nil
+}
+
+func (r *RedisJSONCache) IsNotFound(c context.Context, shortUrl string) (bool, error) {
+	return isNotFound(c, r.client, r.options, shortUrl)
+}
+
+func (r *RedisJSONCache) SetNotFound(c context.Context, shortUrl string) error {
+	return setNotFound(c, r.client, r.options, shortUrl)
+}
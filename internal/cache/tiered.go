@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+// TieredCache answers lookups from a short lived in-process LRU in front of a
+// remote UrlCache. Writes go to both tiers and are broadcast through redis
+// pub/sub so every replica evicts its local copy, the local ttl bounds how
+// long a replica can serve a stale url when a message is lost.
+type TieredCache struct {
+	local      *MemoryCache
+	remote     UrlCache
+	client     *redis.Client
+	instanceID string
+}
+
+func NewTieredCache(
+	local *MemoryCache,
+	remote UrlCache,
+	client *redis.Client,
+) *TieredCache {
+	return &TieredCache{
+		local:      local,
+		remote:     remote,
+		client:     client,
+		instanceID: uuid.NewString(),
+	}
+}
+
+func (t *TieredCache) Get(c context.Context, shortUrl string) (repository.Url, error) {
+	url, err := t.local.Get(c, shortUrl)
+	if err == nil {
+		return url, nil
+	}
+
+	url, err = t.remote.Get(c, shortUrl)
+	if err != nil {
+		return repository.Url{}, err
+	}
+	_ = t.local.Fill(c, url)
+	return url, nil
+}
+
+func (t *TieredCache) Set(c context.Context, url repository.Url) error {
+	err := t.remote.Set(c, url)
+	if err != nil {
+		return err
+	}
+	_ = t.local.Set(c, url)
+	return t.publish(c, url.ShortUrl)
+}
+
+func (t *TieredCache) SetMany(c context.Context, urls []repository.Url) error {
+	err := t.remote.SetMany(c, urls)
+	if err != nil {
+		return err
+	}
+	_ = t.local.SetMany(c, urls)
+	_, err = t.client.Pipelined(c, func(pipe redis.Pipeliner) error {
+		for _, url := range urls {
+			pipe.Publish(c, KeyInvalidationChannel, t.instanceID+":"+url.ShortUrl)
+		}
+		return nil
+	})
+	return err
+}
+
+func (t *TieredCache) Fill(c context.Context, url repository.Url) error {
+	err := t.remote.Fill(c, url)
+	if err != nil {
+		return err
+	}
+	return t.local.Fill(c, url)
+}
+
+func (t *TieredCache) Delete(c context.Context, shortUrl string) error {
+	t.local.Invalidate(shortUrl)
+	err := t.remote.Delete(c, shortUrl)
+	if err != nil {
+		return err
+	}
+	return t.publish(c, shortUrl)
+}
+
+func (t *TieredCache) IncrementVisitedCount(
+	c context.Context,
+	shortUrl string,
+	delta int64,
+) (int64, error) {
+	incremented, err := t.remote.IncrementVisitedCount(c, shortUrl, delta)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = t.local.IncrementVisitedCount(c, shortUrl, delta)
+	return incremented, nil
+}
+
+func (t *TieredCache) IsNotFound(c context.Context, shortUrl string) (bool, error) {
+	notFound, _ := t.local.IsNotFound(c, shortUrl)
+	if notFound {
+		return true, nil
+	}
+	return t.remote.IsNotFound(c, shortUrl)
+}
+
+func (t *TieredCache) SetNotFound(c context.Context, shortUrl string) error {
+	err := t.remote.SetNotFound(c, shortUrl)
+	if err != nil {
+		return err
+	}
+	return t.local.SetNotFound(c, shortUrl)
+}
+
+// Subscribe evicts local entries invalidated by other replicas until c is done.
+func (t *TieredCache) Subscribe(c context.Context) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "TieredCache Subscribe").Logger()
+
+	pubsub := t.client.Subscribe(c, KeyInvalidationChannel)
+	defer func() {
+		err := pubsub.Close()
+		if err != nil && !errors.Is(err, redis.ErrClosed) {
+			logger.Error().
+				Err(err).
+				Msgf("failed closing subscription to channel=%s", KeyInvalidationChannel)
+		}
+	}()
+	logger.Info().Msgf("subscribed to channel=%s", KeyInvalidationChannel)
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-c.Done():
+			logger.Info().Msgf("unsubscribed from channel=%s", KeyInvalidationChannel)
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			instanceID, shortUrl, found := strings.Cut(message.Payload, ":")
+			if !found || instanceID == t.instanceID {
+				continue
+			}
+			logger.Debug().Msgf("invalidating shortUrl=%s from local cache", shortUrl)
+			t.local.Invalidate(shortUrl)
+		}
+	}
+}
+
+func (t *TieredCache) publish(c context.Context, shortUrl string) error {
+	return t.client.Publish(c, KeyInvalidationChannel, t.instanceID+":"+shortUrl).Err()
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Alturino/url-shortener/internal/config"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const (
+	BackendRedisJSON = "redisjson"
+	BackendRedis     = "redis"
+	BackendMemory    = "memory"
+)
+
+var ErrCacheMiss = errors.New("cache miss")
+
+// UrlCache stores urls by their shortUrl. Get and IncrementVisitedCount return
+// ErrCacheMiss when the entry does not exist.
+type UrlCache interface {
+	Get(c context.Context, shortUrl string) (repository.Url, error)
+	// Set overwrites the entry and drops any negative entry of the same shortUrl.
+	Set(c context.Context, url repository.Url) error
+	// SetMany is Set for every url in a single pipeline.
+	SetMany(c context.Context, urls []repository.Url) error
+	// Fill is Set for the read path, it never overwrites an existing entry.
+	Fill(c context.Context, url repository.Url) error
+	Delete(c context.Context, shortUrl string) error
+	IncrementVisitedCount(c context.Context, shortUrl string, delta int64) (int64, error)
+	IsNotFound(c context.Context, shortUrl string) (bool, error)
+	SetNotFound(c context.Context, shortUrl string) error
+}
+
+type Options struct {
+	UrlTTL             time.Duration
+	NotFoundTTL        time.Duration
+	RefreshTTLOnAccess bool
+}
+
+// NewUrlCache creates the UrlCache of the configured backend. When the local
+// tier is enabled the remote backend is wrapped in a TieredCache whose
+// invalidation subscription runs until c is done.
+func NewUrlCache(
+	c context.Context,
+	config config.Cache,
+	client *redis.Client,
+) (UrlCache, error) {
+	options := Options{
+		UrlTTL:             config.UrlTTL,
+		NotFoundTTL:        config.NotFoundTTL,
+		RefreshTTLOnAccess: config.RefreshTTLOnAccess,
+	}
+
+	var remote UrlCache
+	switch config.Backend {
+	case BackendRedisJSON, "":
+		remote = NewRedisJSONCache(client, options)
+	case BackendRedis:
+		remote = NewRedisCache(client, options)
+	case BackendMemory:
+		return NewMemoryCache(config.MemoryCapacity, options), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend=%s", config.Backend)
+	}
+
+	if !config.Local.Enabled {
+		return remote, nil
+	}
+	if config.Local.TTL <= 0 {
+		return nil, fmt.Errorf("cache local ttl=%s must be greater than 0", config.Local.TTL)
+	}
+
+	notFoundTTL := config.NotFoundTTL
+	if notFoundTTL > config.Local.TTL {
+		notFoundTTL = config.Local.TTL
+	}
+	local := NewMemoryCache(
+		config.Local.Capacity,
+		Options{UrlTTL: config.Local.TTL, NotFoundTTL: notFoundTTL},
+	)
+	tiered := NewTieredCache(local, remote, client)
+	go tiered.Subscribe(c)
+
+	return tiered, nil
+}
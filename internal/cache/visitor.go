@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DayLayout = "2006-01-02"
+
+	defaultVisitorTTL = 48 * time.Hour
+)
+
+type Visitor struct {
+	UrlID uuid.UUID
+	Day   time.Time
+	ID    string
+}
+
+// VisitorCache tracks the approximate unique visitors of a url per day in a
+// HyperLogLog keyed by the url id, so a short url issued again after a purge
+// starts counting from zero. The keys expire after ttl so they only cover
+// recent days.
+type VisitorCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewVisitorCache(client *redis.Client, ttl time.Duration) *VisitorCache {
+	if ttl <= 0 {
+		ttl = defaultVisitorTTL
+	}
+	return &VisitorCache{client: client, ttl: ttl}
+}
+
+// TTL is how long after its first visitor a day is answered from redis.
+func (v *VisitorCache) TTL() time.Duration {
+	return v.ttl
+}
+
+func (v *VisitorCache) Add(c context.Context, visitors []Visitor) error {
+	if len(visitors) == 0 {
+		return nil
+	}
+
+	_, err := v.client.Pipelined(c, func(pipe redis.Pipeliner) error {
+		for _, visitor := range visitors {
+			key := visitorKey(visitor.UrlID, visitor.Day)
+			pipe.PFAdd(c, key, visitor.ID)
+			pipe.ExpireNX(c, key, v.ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// Count returns the estimated unique visitors of the url with urlID on each
+// day, days without a key are counted as 0.
+func (v *VisitorCache) Count(
+	c context.Context,
+	urlID uuid.UUID,
+	days []time.Time,
+) ([]int64, error) {
+	counts := make([]*redis.IntCmd, 0, len(days))
+	_, err := v.client.Pipelined(c, func(pipe redis.Pipeliner) error {
+		for _, day := range days {
+			counts = append(counts, pipe.PFCount(c, visitorKey(urlID, day)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	visitors := make([]int64, 0, len(days))
+	for _, count := range counts {
+		visitors = append(visitors, count.Val())
+	}
+	return visitors, nil
+}
+
+func visitorKey(urlID uuid.UUID, day time.Time) string {
+	return fmt.Sprintf(KeyVisitors, urlID, day.UTC().Format(DayLayout))
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
 
@@ -8,28 +10,148 @@ import (
 )
 
 type Config struct {
-	Env         string `mapstructure:"env"`
-	Database    `mapstructure:"db"`
-	Cache       `mapstructure:"cache"`
-	Application `mapstructure:"application"`
+	Env          string `mapstructure:"env"`
+	Database     `mapstructure:"db"`
+	Cache        `mapstructure:"cache"`
+	Application  `mapstructure:"application"`
+	ShortCode    `mapstructure:"short_code"`
+	VisitCounter `mapstructure:"visit_counter"`
+	Analytics    `mapstructure:"analytics"`
+	Expiration   `mapstructure:"expiration"`
+	Deletion     `mapstructure:"deletion"`
+	Activation   `mapstructure:"activation"`
+	Auth         `mapstructure:"auth"`
+	RateLimit    `mapstructure:"rate_limit"`
+	Destination  `mapstructure:"destination"`
+	Policy       `mapstructure:"policy"`
+	Batch        `mapstructure:"batch"`
 }
 
 type Application struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Host              string `mapstructure:"host"`
+	Port              int    `mapstructure:"port"`
+	TrustProxyHeaders bool   `mapstructure:"trust_proxy_headers"`
+	TrustedProxyHops  int    `mapstructure:"trusted_proxy_hops"`
 }
 
 type Cache struct {
-	Host     string `mapstructure:"host"`
-	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
-	Port     int    `mapstructure:"port"`
+	Host               string        `mapstructure:"host"`
+	Username           string        `mapstructure:"username"`
+	Password           string        `mapstructure:"password" json:"-"`
+	Port               int           `mapstructure:"port"`
+	Backend            string        `mapstructure:"backend"`
+	MemoryCapacity     int           `mapstructure:"memory_capacity"`
+	UrlTTL             time.Duration `mapstructure:"url_ttl"`
+	NotFoundTTL        time.Duration `mapstructure:"not_found_ttl"`
+	RefreshTTLOnAccess bool          `mapstructure:"refresh_ttl_on_access"`
+	Local              LocalCache    `mapstructure:"local"`
+}
+
+type LocalCache struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Capacity int           `mapstructure:"capacity"`
+	TTL      time.Duration `mapstructure:"ttl"`
+}
+
+type ShortCode struct {
+	Generator      string `mapstructure:"generator"`
+	Salt           string `mapstructure:"salt" json:"-"`
+	Length         int    `mapstructure:"length"`
+	MaxRetries     int    `mapstructure:"max_retries"`
+	AliasMinLength int    `mapstructure:"alias_min_length"`
+	AliasMaxLength int    `mapstructure:"alias_max_length"`
+}
+
+type VisitCounter struct {
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+type Analytics struct {
+	UniqueVisitors bool          `mapstructure:"unique_visitors"`
+	IPMode         string        `mapstructure:"ip_mode"`
+	IPSalt         string        `mapstructure:"ip_salt" json:"-"`
+	BufferSize     int           `mapstructure:"buffer_size"`
+	BatchSize      int           `mapstructure:"batch_size"`
+	FlushInterval  time.Duration `mapstructure:"flush_interval"`
+	VisitorTTL     time.Duration `mapstructure:"visitor_ttl"`
+	RollupInterval time.Duration `mapstructure:"rollup_interval"`
+}
+
+type Expiration struct {
+	FallbackUrl     string        `mapstructure:"fallback_url"`
+	ReaperInterval  time.Duration `mapstructure:"reaper_interval"`
+	ReaperMode      string        `mapstructure:"reaper_mode"`
+	ReaperBatchSize int32         `mapstructure:"reaper_batch_size"`
+}
+
+// Deletion keeps deleted urls as tombstones for Quarantine so their short
+// urls can be restored and are not reissued, the purger removes them after.
+type Deletion struct {
+	Quarantine     time.Duration `mapstructure:"quarantine"`
+	PurgeInterval  time.Duration `mapstructure:"purge_interval"`
+	PurgeBatchSize int32         `mapstructure:"purge_batch_size"`
+}
+
+type Activation struct {
+	NotYetActiveMessage string `mapstructure:"not_yet_active_message"`
+	NotYetActiveUrl     string `mapstructure:"not_yet_active_url"`
+	EndedMessage        string `mapstructure:"ended_message"`
+	EndedUrl            string `mapstructure:"ended_url"`
+}
+
+type Auth struct {
+	BootstrapAdminKey string `mapstructure:"bootstrap_admin_key" json:"-"`
+	JWT               JWT    `mapstructure:"jwt"`
+}
+
+type JWT struct {
+	Enabled             bool          `mapstructure:"enabled"`
+	JWKSSource          string        `mapstructure:"jwks_source"`
+	JWKSRefreshInterval time.Duration `mapstructure:"jwks_refresh_interval"`
+	Issuer              string        `mapstructure:"issuer"`
+	Audience            string        `mapstructure:"audience"`
+	OwnerClaim          string        `mapstructure:"owner_claim"`
+	RolesClaim          string        `mapstructure:"roles_claim"`
+	DefaultRole         string        `mapstructure:"default_role"`
+	Leeway              time.Duration `mapstructure:"leeway"`
+}
+
+type RateLimit struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Create   RateLimitRule `mapstructure:"create"`
+	Redirect RateLimitRule `mapstructure:"redirect"`
+	Stats    RateLimitRule `mapstructure:"stats"`
+}
+
+type RateLimitRule struct {
+	Limit  int64         `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+}
+
+type Destination struct {
+	AllowedSchemes        []string `mapstructure:"allowed_schemes"`
+	MaxLength             int      `mapstructure:"max_length"`
+	OwnHosts              []string `mapstructure:"own_hosts"`
+	AllowPrivateAddresses bool     `mapstructure:"allow_private_addresses"`
+}
+
+type Policy struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	RulesFile       string        `mapstructure:"rules_file"`
+	ReloadInterval  time.Duration `mapstructure:"reload_interval"`
+	CheckOnRedirect bool          `mapstructure:"check_on_redirect"`
+	WarningUrl      string        `mapstructure:"warning_url"`
+}
+
+type Batch struct {
+	MaxItems     int  `mapstructure:"max_items"`
+	AllOrNothing bool `mapstructure:"all_or_nothing"`
 }
 
 type Database struct {
 	Host           string `mapstructure:"host"`
 	DbName         string `mapstructure:"name"`
-	Password       string `mapstructure:"password"`
+	Password       string `mapstructure:"password" json:"-"`
 	Username       string `mapstructure:"username"`
 	MigrationPath  string `mapstructure:"migration_path"`
 	TimeZone       string `mapstructure:"timezone"`
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/request"
+	"github.com/Alturino/url-shortener/internal/response"
+	"github.com/Alturino/url-shortener/internal/service"
+)
+
+type ApiKeyController struct {
+	service *service.ApiKeyService
+}
+
+func AttachApiKeyController(mux *http.ServeMux, service *service.ApiKeyService) {
+	controller := ApiKeyController{service: service}
+	mux.HandleFunc("POST /admin/api-keys", controller.CreateApiKey)
+	mux.HandleFunc("DELETE /admin/api-keys/{id}", controller.RevokeApiKey)
+}
+
+func (a *ApiKeyController) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "ApiKeyController CreateApiKey")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "CreateApiKey").Logger()
+
+	logger.Info().Msg("decoding requestBody")
+	req := request.ApiKeyRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		err = fmt.Errorf(
+			"failed decoding requestBody with error=%w: %w",
+			service.ErrInvalidRequest,
+			err,
+		)
+		logger.Error().Err(err).Msg("failed decoding requestBody")
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msg("decoded requestBody")
+
+	ownerID := uuid.Nil
+	if req.OwnerID != "" {
+		ownerID, err = uuid.Parse(req.OwnerID)
+		if err != nil {
+			err = fmt.Errorf(
+				"failed parsing ownerId=%s with error=%w: %w",
+				req.OwnerID,
+				service.ErrInvalidRequest,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			response.WriteProblem(c, w, map[string]string{}, err)
+			return
+		}
+	}
+
+	logger.Info().Msg("creating api key")
+	c = logger.WithContext(c)
+	created, key, err := a.service.CreateApiKey(c, service.CreateApiKeyParams{
+		OwnerID: ownerID,
+		Name:    req.Name,
+		Role:    req.Role,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed creating api key with error=%s", err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Str(log.KeyApiKeyID, created.ID.String()).Msg("created api key")
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status":  "success",
+			"message": fmt.Sprintf("created apiKeyId=%s", created.ID.String()),
+			"data":    response.NewApiKeyResponse(created, key),
+		},
+		http.StatusCreated,
+	)
+}
+
+func (a *ApiKeyController) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "ApiKeyController RevokeApiKey")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "RevokeApiKey").Logger()
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		err = fmt.Errorf(
+			"failed parsing apiKeyId=%s with error=%w: %w",
+			r.PathValue("id"),
+			service.ErrInvalidRequest,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+
+	logger.Info().Msgf("revoking apiKeyId=%s", id.String())
+	c = logger.WithContext(c)
+	revoked, err := a.service.RevokeApiKey(c, id)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msgf("failed revoking apiKeyId=%s with error=%s", id.String(), err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf("revoked apiKeyId=%s", id.String())
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status":  "success",
+			"message": fmt.Sprintf("revoked apiKeyId=%s", id.String()),
+			"data":    response.NewApiKeyResponse(revoked, ""),
+		},
+		http.StatusOK,
+	)
+}
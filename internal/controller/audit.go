@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/response"
+	"github.com/Alturino/url-shortener/internal/service"
+)
+
+type AuditController struct {
+	service *service.AuditService
+}
+
+func AttachAuditController(mux *http.ServeMux, service *service.AuditService) {
+	controller := AuditController{service: service}
+	mux.HandleFunc("GET /admin/audit", controller.ListAuditLogs)
+}
+
+func (a *AuditController) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "AuditController ListAuditLogs")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "ListAuditLogs").Logger()
+
+	if !requireRole(c, w, auth.RoleAdmin) {
+		return
+	}
+
+	logger.Info().Msg("parsing audit query")
+	param, err := parseAuditParams(r.URL.Query())
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msg("parsed audit query")
+
+	logger.Info().Msg("listing audit logs")
+	c = logger.WithContext(c)
+	page, err := a.service.ListAuditLogs(c, param)
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed listing audit logs with error=%s", err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf("listed %d audit logs", len(page.Logs))
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status":  "success",
+			"message": fmt.Sprintf("found %d audit logs", len(page.Logs)),
+			"data":    page,
+		},
+		http.StatusOK,
+	)
+}
+
+func parseAuditParams(query url.Values) (service.ListAuditLogsParams, error) {
+	param := service.ListAuditLogsParams{
+		Action:   query.Get("action"),
+		ShortUrl: query.Get("short_url"),
+		Hashcode: query.Get("hashcode"),
+		Cursor:   query.Get("cursor"),
+	}
+
+	if actorID := query.Get("actor_id"); actorID != "" {
+		parsed, err := uuid.Parse(actorID)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing actor_id=%s with error=%w: %w",
+				actorID,
+				service.ErrInvalidAuditParams,
+				err,
+			)
+		}
+		param.ActorID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+
+	times := map[string]**time.Time{
+		"from": &param.From,
+		"to":   &param.To,
+	}
+	for key, target := range times {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing %s=%s with error=%w: %w",
+				key,
+				value,
+				service.ErrInvalidAuditParams,
+				err,
+			)
+		}
+		*target = &parsed
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing limit=%s with error=%w: %w",
+				limit,
+				service.ErrInvalidAuditParams,
+				err,
+			)
+		}
+		param.Limit = int32(parsed)
+	}
+	return param, nil
+}
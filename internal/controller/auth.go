@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/response"
+	"github.com/Alturino/url-shortener/internal/service"
+)
+
+// requireRole responds 401 without a principal and 403 when the role of the
+// principal is below role, it reports whether the request may continue.
+func requireRole(c context.Context, w http.ResponseWriter, role string) bool {
+	principal, ok := auth.PrincipalFromContext(c)
+	if !ok {
+		response.WriteProblem(c, w, map[string]string{}, fmt.Errorf(
+			"request has no credentials with error=%w",
+			service.ErrUnauthenticated,
+		))
+		return false
+	}
+	if !principal.HasRole(role) {
+		response.WriteProblem(c, w, map[string]string{}, fmt.Errorf(
+			"role=%s is required but ownerId=%s has role=%s with error=%w",
+			role,
+			principal.OwnerID.String(),
+			principal.Role,
+			service.ErrForbidden,
+		))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/request"
+	"github.com/Alturino/url-shortener/internal/response"
+	"github.com/Alturino/url-shortener/internal/service"
+)
+
+var ndjsonMediaTypes = map[string]struct{}{
+	"application/x-ndjson": {},
+	"application/ndjson":   {},
+	"application/jsonl":    {},
+}
+
+// InsertUrls creates the urls of a JSON array or an NDJSON stream, the
+// all_or_nothing query overrides batch.all_or_nothing.
+func (u *UrlController) InsertUrls(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "UrlController InsertUrls")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "InsertUrls").Logger()
+
+	if !requireRole(c, w, auth.RoleEditor) {
+		return
+	}
+
+	param := service.BatchInsertUrlsParams{}
+	if allOrNothing := r.URL.Query().Get("all_or_nothing"); allOrNothing != "" {
+		parsed, err := strconv.ParseBool(allOrNothing)
+		if err != nil {
+			err = fmt.Errorf(
+				"failed parsing all_or_nothing=%s with error=%w: %w",
+				allOrNothing,
+				service.ErrInvalidBatch,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			response.WriteProblem(c, w, map[string]string{}, err)
+			return
+		}
+		param.AllOrNothing = &parsed
+	}
+
+	logger.Info().Msg("decoding requestBody")
+	reqs, err := u.decodeBatch(r)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed decoding requestBody")
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf("decoded requestBody of %d urls", len(reqs))
+
+	param.Urls = make([]service.InsertUrlParams, 0, len(reqs))
+	for _, req := range reqs {
+		param.Urls = append(param.Urls, service.InsertUrlParams{
+			Url:            req.Url,
+			Alias:          req.Alias,
+			RedirectStatus: req.RedirectStatus,
+			ExpiresAt:      req.ExpiresAt,
+			MaxClicks:      req.MaxClicks,
+			ActiveFrom:     req.ActiveFrom,
+			ActiveUntil:    req.ActiveUntil,
+		})
+	}
+
+	logger.Info().Msgf("inserting batch of %d urls", len(param.Urls))
+	c = logger.WithContext(c)
+	result, err := u.service.InsertUrls(c, param)
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed inserting batch with error=%s", err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf(
+		"inserted batch of %d urls inserted=%d failed=%d",
+		len(reqs),
+		result.Inserted,
+		result.Failed,
+	)
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status":  "success",
+			"message": fmt.Sprintf("inserted %d of %d urls", result.Inserted, len(reqs)),
+			"data":    result,
+		},
+		http.StatusOK,
+	)
+}
+
+// decodeBatch streams the url requests of the body, an NDJSON body has one
+// request per line and any other body is a JSON array. It stops once more
+// than batch.max_items urls are read.
+func (u *UrlController) decodeBatch(r *http.Request) ([]request.UrlRequest, error) {
+	maxItems := u.batchConfig.MaxItems
+	if maxItems <= 0 {
+		maxItems = service.DefaultBatchMaxItems
+	}
+	decoder := json.NewDecoder(r.Body)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	_, ndjson := ndjsonMediaTypes[mediaType]
+	if !ndjson {
+		token, err := decoder.Token()
+		if err != nil || token != json.Delim('[') {
+			return nil, fmt.Errorf(
+				"requestBody must be a json array or ndjson with error=%w",
+				service.ErrInvalidBatch,
+			)
+		}
+	}
+
+	reqs := []request.UrlRequest{}
+	for {
+		if !ndjson && !decoder.More() {
+			break
+		}
+		req := request.UrlRequest{}
+		err := decoder.Decode(&req)
+		if ndjson && errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed decoding url at index=%d with error=%w: %w",
+				len(reqs),
+				service.ErrInvalidBatch,
+				err,
+			)
+		}
+		reqs = append(reqs, req)
+		if len(reqs) > maxItems {
+			return nil, fmt.Errorf(
+				"batch must not contain more than %d urls with error=%w",
+				maxItems,
+				service.ErrInvalidBatch,
+			)
+		}
+	}
+	return reqs, nil
+}
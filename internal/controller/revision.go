@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/response"
+	"github.com/Alturino/url-shortener/internal/service"
+)
+
+func (u *UrlController) GetUrlHistory(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "UrlController GetUrlHistory")
+	defer span.End()
+
+	shortUrl := r.PathValue("shortUrl")
+
+	logger := zerolog.Ctx(c).
+		With().
+		Str(log.KeyProcess, "GetUrlHistory").
+		Str(log.KeyShortUrl, shortUrl).
+		Logger()
+
+	if !requireRole(c, w, auth.RoleViewer) {
+		return
+	}
+
+	logger.Info().Msgf("finding history of shortUrl=%s", shortUrl)
+	c = logger.WithContext(c)
+	history, err := u.service.GetUrlHistory(c, shortUrl)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msgf("failed finding history of shortUrl=%s with error=%s", shortUrl, err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf("found history of shortUrl=%s", shortUrl)
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status":  "success",
+			"message": fmt.Sprintf("found history of shortUrl=%s", shortUrl),
+			"data":    history,
+		},
+		http.StatusOK,
+	)
+}
+
+func (u *UrlController) RollbackUrl(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "UrlController RollbackUrl")
+	defer span.End()
+
+	shortUrl := r.PathValue("shortUrl")
+
+	logger := zerolog.Ctx(c).
+		With().
+		Str(log.KeyProcess, "RollbackUrl").
+		Str(log.KeyShortUrl, shortUrl).
+		Logger()
+
+	if !requireRole(c, w, auth.RoleEditor) {
+		return
+	}
+
+	revision, err := strconv.ParseInt(r.PathValue("revision"), 10, 32)
+	if err != nil || revision <= 0 {
+		err = fmt.Errorf(
+			"revision=%s must be a positive integer with error=%w",
+			r.PathValue("revision"),
+			service.ErrInvalidRequest,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+
+	logger.Info().Msgf("rolling back shortUrl=%s to revision=%d", shortUrl, revision)
+	c = logger.WithContext(c)
+	updated, err := u.service.RollbackUrl(c, shortUrl, int32(revision))
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msgf("failed rolling back shortUrl=%s with error=%s", shortUrl, err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf("rolled back shortUrl=%s to url=%s", shortUrl, updated.Url)
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status": "success",
+			"message": fmt.Sprintf(
+				"rolled back shortUrl=%s to revision=%d",
+				shortUrl,
+				revision,
+			),
+			"data": updated,
+		},
+		http.StatusOK,
+	)
+}
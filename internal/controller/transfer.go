@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/response"
+	"github.com/Alturino/url-shortener/internal/service"
+	"github.com/Alturino/url-shortener/internal/transfer"
+)
+
+type TransferController struct {
+	service *service.UrlService
+}
+
+func AttachTransferController(mux *http.ServeMux, service *service.UrlService) {
+	controller := TransferController{service: service}
+	mux.HandleFunc("GET /admin/export", controller.ExportUrls)
+	mux.HandleFunc("POST /admin/import", controller.ImportUrls)
+}
+
+// exportWriter remembers whether anything reached the client, an export that
+// fails before that can still be answered with a problem.
+type exportWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	e.written = true
+	return e.ResponseWriter.Write(p)
+}
+
+// ExportUrls streams every url as ndjson or, with format=csv, as csv. The
+// server write timeout is lifted since an export of millions of urls outlasts
+// it.
+func (t *TransferController) ExportUrls(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "TransferController ExportUrls")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "ExportUrls").Logger()
+
+	if !requireRole(c, w, auth.RoleAdmin) {
+		return
+	}
+
+	format := transfer.FormatNdjson
+	var err error
+	if query := r.URL.Query().Get("format"); query != "" {
+		format, err = transfer.ParseFormat(query)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+
+	err = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil {
+		logger.Warn().Err(err).Msgf("failed lifting write deadline with error=%s", err)
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf(`attachment; filename="urls.%s"`, format),
+	)
+	writer := &exportWriter{ResponseWriter: w}
+
+	logger.Info().Msgf("exporting urls as format=%s", format)
+	c = logger.WithContext(c)
+	exported, err := t.service.ExportUrls(c, transfer.NewWriter(format, writer))
+	if err != nil && !writer.written {
+		logger.Error().Err(err).Msgf("failed exporting urls with error=%s", err.Error())
+		w.Header().Del("Content-Disposition")
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	if err != nil {
+		// the status is already sent, aborting the response is the only way
+		// to tell the client the export is truncated
+		logger.Error().
+			Err(err).
+			Msgf("failed exporting urls after %d urls with error=%s", exported, err.Error())
+		panic(http.ErrAbortHandler)
+	}
+	logger.Info().Msgf("exported %d urls as format=%s", exported, format)
+}
+
+// ImportUrls imports a csv or ndjson export, the format query falls back to
+// the Content-Type and on_conflict picks skip, overwrite or fail.
+func (t *TransferController) ImportUrls(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "TransferController ImportUrls")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "ImportUrls").Logger()
+
+	if !requireRole(c, w, auth.RoleAdmin) {
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = string(transfer.FormatNdjson)
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "text/csv" {
+			format = string(transfer.FormatCsv)
+		}
+	}
+	parsed, err := transfer.ParseFormat(format)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	strategy, err := service.ParseImportStrategy(query.Get("on_conflict"))
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	err = controller.SetReadDeadline(time.Time{})
+	if err == nil {
+		err = controller.SetWriteDeadline(time.Time{})
+	}
+	if err != nil {
+		logger.Warn().Err(err).Msgf("failed lifting deadlines with error=%s", err)
+	}
+
+	logger.Info().Msgf("importing urls as format=%s on_conflict=%s", parsed, strategy)
+	c = logger.WithContext(c)
+	result, err := t.service.ImportUrls(c, transfer.NewReader(parsed, r.Body), strategy)
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed importing urls with error=%s", err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf(
+		"imported urls inserted=%d updated=%d skipped=%d",
+		result.Inserted,
+		result.Updated,
+		result.Skipped,
+	)
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status": "success",
+			"message": fmt.Sprintf(
+				"imported %d urls",
+				result.Inserted+result.Updated,
+			),
+			"data": result,
+		},
+		http.StatusOK,
+	)
+}
@@ -2,14 +2,23 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/config"
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
 	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
 	"github.com/Alturino/url-shortener/internal/request"
 	"github.com/Alturino/url-shortener/internal/response"
 	"github.com/Alturino/url-shortener/internal/service"
@@ -20,16 +29,41 @@ const name = "github.com/Alturino/url-shortener"
 var tracer = otel.Tracer(name)
 
 type UrlController struct {
-	service *service.UrlService
+	service          *service.UrlService
+	expirationConfig config.Expiration
+	activationConfig config.Activation
+	policyConfig     config.Policy
+	batchConfig      config.Batch
 }
 
-func AttachUrlController(mux *http.ServeMux, service *service.UrlService) {
-	controller := UrlController{service: service}
-	mux.HandleFunc("GET /urls/{shortUrl}", controller.GetUrlByShortUrl)
+func AttachUrlController(
+	mux *http.ServeMux,
+	service *service.UrlService,
+	expirationConfig config.Expiration,
+	activationConfig config.Activation,
+	policyConfig config.Policy,
+	batchConfig config.Batch,
+) {
+	controller := UrlController{
+		service:          service,
+		expirationConfig: expirationConfig,
+		activationConfig: activationConfig,
+		policyConfig:     policyConfig,
+		batchConfig:      batchConfig,
+	}
+	mux.HandleFunc("GET /{shortUrl}", controller.RedirectUrl)
+	mux.HandleFunc("HEAD /{shortUrl}", controller.RedirectUrl)
+	mux.HandleFunc("GET /urls/{shortUrl}", controller.GetUrlMetadata)
 	mux.HandleFunc("GET /urls/{shortUrl}/stats", controller.GetUrlByShortUrlDetail)
 	mux.HandleFunc("PUT /urls/{shortUrl}", controller.UpdateUrl)
 	mux.HandleFunc("DELETE /urls/{shortUrl}", controller.DeleteUrl)
+	mux.HandleFunc("POST /urls/{shortUrl}/restore", controller.RestoreUrl)
+	mux.HandleFunc("GET /urls/{shortUrl}/history", controller.GetUrlHistory)
+	mux.HandleFunc("POST /urls/{shortUrl}/rollback/{revision}", controller.RollbackUrl)
 	mux.HandleFunc("POST /urls", controller.InsertUrl)
+	mux.HandleFunc("POST /urls/batch", controller.InsertUrls)
+	mux.HandleFunc("GET /urls", controller.ListUrls)
+	mux.HandleFunc("GET /warning/{shortUrl}", controller.WarningPage)
 }
 
 func (u *UrlController) InsertUrl(w http.ResponseWriter, r *http.Request) {
@@ -38,61 +72,53 @@ func (u *UrlController) InsertUrl(w http.ResponseWriter, r *http.Request) {
 
 	logger := zerolog.Ctx(c).With().Logger()
 
+	if !requireRole(c, w, auth.RoleEditor) {
+		return
+	}
+
 	logger.Info().Msg("decoding requestBody")
 	req := request.UrlRequest{}
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
+		err = fmt.Errorf(
+			"failed decoding requestBody with error=%w: %w",
+			service.ErrInvalidRequest,
+			err,
+		)
 		logger.Error().
 			Err(err).
 			Str(log.KeyProcess, "InsertUrl").
 			Msg("failed decoding requestBody")
-		response.WriteJsonResponse(
-			r.Context(),
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
-		)
+		response.WriteProblem(r.Context(), w, map[string]string{}, err)
 		return
 	}
 	logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
 		return c.Any(log.KeyRequestBody, req).
 			Str(log.KeyProcess, "InsertUrl").
-			Str(log.KeyUrl, req.Url)
+			Str(log.KeyUrl, req.Url).
+			Str(log.KeyAlias, req.Alias)
 	})
 	c = logger.WithContext(r.Context())
 	logger.Info().Msg("decoded requestBody")
 
-	logger.Info().Msgf("validating url=%s", req.Url)
-	validatedUrl, err := url.Parse(req.Url)
-	if err != nil {
-		logger.Error().
-			Err(err).
-			Msgf("failed validating url=%s with error=%s", req.Url, err.Error())
-		response.WriteJsonResponse(
-			c,
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
-		)
-		return
-	}
-	logger.Info().Msgf("validated url=%s", req.Url)
-
 	logger.Info().Msgf("inserting url=%s", req.Url)
-	inserted, err := u.service.InsertUrl(c, *validatedUrl)
+	inserted, err := u.service.InsertUrl(
+		c,
+		service.InsertUrlParams{
+			Url:            req.Url,
+			Alias:          req.Alias,
+			RedirectStatus: req.RedirectStatus,
+			ExpiresAt:      req.ExpiresAt,
+			MaxClicks:      req.MaxClicks,
+			ActiveFrom:     req.ActiveFrom,
+			ActiveUntil:    req.ActiveUntil,
+		},
+	)
 	if err != nil {
 		logger.Error().
 			Err(err).
 			Msgf("failed inserting url=%s with error=%s", req.Url, err.Error())
-		response.WriteJsonResponse(
-			c,
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
-		)
+		response.WriteProblem(c, w, map[string]string{}, err)
 		return
 	}
 	logger.Info().
@@ -122,17 +148,20 @@ func (u *UrlController) UpdateUrl(w http.ResponseWriter, r *http.Request) {
 		Str(log.KeyProcess, "UrlController UpdateUrl").
 		Logger()
 
-	req := request.UrlRequest{}
+	if !requireRole(c, w, auth.RoleEditor) {
+		return
+	}
+
+	req := request.UpdateUrlRequest{}
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed decoding requestBody")
-		response.WriteJsonResponse(
-			c,
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
+		err = fmt.Errorf(
+			"failed decoding requestBody with error=%w: %w",
+			service.ErrInvalidRequest,
+			err,
 		)
+		logger.Error().Err(err).Msg("failed decoding requestBody")
+		response.WriteProblem(c, w, map[string]string{}, err)
 		return
 	}
 	logger = logger.With().
@@ -142,37 +171,29 @@ func (u *UrlController) UpdateUrl(w http.ResponseWriter, r *http.Request) {
 		Logger()
 	logger.Info().Msg("decoded requestBody")
 
-	logger.Info().Msgf("validating url=%s", req.Url)
-	validatedUrl, err := url.Parse(req.Url)
-	if err != nil {
-		logger.Error().
-			Err(err).
-			Msgf("failed validating url=%s with error=%s", req.Url, err.Error())
-		response.WriteJsonResponse(
-			c,
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
-		)
-		return
-	}
-	logger.Info().Msgf("validated url=%s", req.Url)
-
 	logger.Info().Msgf("updating url=%s", req.Url)
 	c = logger.WithContext(c)
-	updated, err := u.service.UpdateUrl(c, *validatedUrl, shortUrl)
+	updated, err := u.service.UpdateUrl(
+		c,
+		shortUrl,
+		service.UpdateUrlParams{
+			Url:              req.Url,
+			RedirectStatus:   req.RedirectStatus,
+			ExpiresAt:        req.ExpiresAt,
+			MaxClicks:        req.MaxClicks,
+			ActiveFrom:       req.ActiveFrom,
+			ActiveUntil:      req.ActiveUntil,
+			ClearExpiresAt:   req.ClearExpiresAt,
+			ClearMaxClicks:   req.ClearMaxClicks,
+			ClearActiveFrom:  req.ClearActiveFrom,
+			ClearActiveUntil: req.ClearActiveUntil,
+		},
+	)
 	if err != nil {
 		logger.Error().
 			Err(err).
 			Msgf("failed updating url=%s with error=%s", req.Url, err.Error())
-		response.WriteJsonResponse(
-			c,
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
-		)
+		response.WriteProblem(c, w, map[string]string{}, err)
 		return
 	}
 	logger.Info().
@@ -203,19 +224,18 @@ func (u *UrlController) DeleteUrl(w http.ResponseWriter, r *http.Request) {
 		Str(log.KeyShortUrl, shortUrl).
 		Logger()
 
+	if !requireRole(c, w, auth.RoleEditor) {
+		return
+	}
+
 	logger.Info().Msgf("deleting shortUrl=%s", shortUrl)
-	deleted, err := u.service.DeleteUrl(r.Context(), shortUrl)
+	c = logger.WithContext(c)
+	deleted, err := u.service.DeleteUrl(c, shortUrl)
 	if err != nil {
 		logger.Error().
 			Err(err).
 			Msgf("failed deleting shortUrl=%s with error=%s", shortUrl, err.Error())
-		response.WriteJsonResponse(
-			c,
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
-		)
+		response.WriteProblem(c, w, map[string]string{}, err)
 		return
 	}
 	logger.Info().
@@ -234,31 +254,240 @@ func (u *UrlController) DeleteUrl(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
-func (u *UrlController) GetUrlByShortUrl(w http.ResponseWriter, r *http.Request) {
-	c, span := tracer.Start(r.Context(), "UrlController GetUrlByShortUrl")
+// RestoreUrl brings back a deleted url that is still in quarantine.
+func (u *UrlController) RestoreUrl(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "UrlController RestoreUrl")
 	defer span.End()
 
 	shortUrl := r.PathValue("shortUrl")
+
 	logger := zerolog.Ctx(c).
 		With().
-		Str(log.KeyProcess, "GetUrlByShortUrlDetail").
+		Str(log.KeyProcess, "RestoreUrl").
+		Str(log.KeyShortUrl, shortUrl).
+		Logger()
+
+	if !requireRole(c, w, auth.RoleEditor) {
+		return
+	}
+
+	logger.Info().Msgf("restoring shortUrl=%s", shortUrl)
+	c = logger.WithContext(c)
+	restored, err := u.service.RestoreUrl(c, shortUrl)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msgf("failed restoring shortUrl=%s with error=%s", shortUrl, err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().
+		Msgf("restored url=%s shortUrl=%s", restored.Url, restored.ShortUrl)
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status": "success",
+			"message": fmt.Sprintf(
+				"restored url=%s to shortUrl=%s",
+				restored.Url,
+				restored.ShortUrl,
+			),
+			"data": restored,
+		},
+		http.StatusOK,
+	)
+}
+
+func (u *UrlController) RedirectUrl(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "UrlController RedirectUrl")
+	defer span.End()
+
+	shortUrl := r.PathValue("shortUrl")
+	logger := zerolog.Ctx(c).
+		With().
+		Str(log.KeyProcess, "RedirectUrl").
 		Str(log.KeyShortUrl, shortUrl).
 		Logger()
 
 	logger.Info().Msgf("finding shortUrl=%s", shortUrl)
 	c = logger.WithContext(c)
-	existed, err := u.service.GetUrlByShortUrl(c, shortUrl)
+	var existed repository.Url
+	var err error
+	if r.Method == http.MethodHead {
+		existed, err = u.service.ResolveUrl(c, shortUrl)
+	} else {
+		existed, err = u.service.GetUrlByShortUrl(c, shortUrl, service.Visit{
+			Referrer:       r.Referer(),
+			UserAgent:      r.UserAgent(),
+			ClientIP:       request.ClientIP(r),
+			AcceptLanguage: r.Header.Get("Accept-Language"),
+		})
+	}
+	if errors.Is(err, service.ErrUrlBlocked) {
+		logger.Info().Msg(err.Error())
+		warningUrl := u.policyConfig.WarningUrl
+		if warningUrl == "" {
+			warningUrl = "/warning/" + url.PathEscape(shortUrl)
+		}
+		logger.Info().Msgf("redirecting shortUrl=%s to warningUrl=%s", shortUrl, warningUrl)
+		http.Redirect(w, r, warningUrl, http.StatusFound)
+		return
+	}
+	if errors.Is(err, service.ErrUrlNotYetActive) {
+		logger.Info().Msg(err.Error())
+		if existed.ActiveFrom != nil {
+			w.Header().Set("Retry-After", existed.ActiveFrom.UTC().Format(http.TimeFormat))
+		}
+		u.writeInactive(
+			w,
+			r,
+			u.activationConfig.NotYetActiveUrl,
+			u.activationConfig.NotYetActiveMessage,
+			http.StatusNotFound,
+		)
+		return
+	}
+	if errors.Is(err, service.ErrUrlActivationEnded) {
+		logger.Info().Msg(err.Error())
+		u.writeInactive(
+			w,
+			r,
+			u.activationConfig.EndedUrl,
+			u.activationConfig.EndedMessage,
+			http.StatusGone,
+		)
+		return
+	}
+	if errors.Is(err, service.ErrUrlExpired) {
+		logger.Info().Msg(err.Error())
+		fallbackUrl := u.expirationConfig.FallbackUrl
+		if fallbackUrl != "" {
+			logger.Info().Msgf("redirecting shortUrl=%s to fallbackUrl=%s", shortUrl, fallbackUrl)
+			http.Redirect(w, r, fallbackUrl, http.StatusFound)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return
+	}
 	if err != nil {
 		logger.Error().
 			Err(err).
 			Msgf("failed finding shortUrl=%s with error=%s", shortUrl, err.Error())
-		response.WriteJsonResponse(
-			c,
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
+		statusCode := apperrors.Classify(err).Kind.StatusCode()
+		http.Error(w, http.StatusText(statusCode), statusCode)
+		return
+	}
+	logger.Info().
+		Msgf("found url=%s shortUrl=%s", existed.Url, existed.ShortUrl)
+
+	logger.Info().
+		Int16(log.KeyRedirectStatus, existed.RedirectStatus).
+		Msgf("redirecting shortUrl=%s to url=%s", shortUrl, existed.Url)
+	http.Redirect(w, r, existed.Url, int(existed.RedirectStatus))
+}
+
+// writeInactive redirects to redirectUrl when it is configured and otherwise
+// responds statusCode with message, falling back to the status text.
+func (u *UrlController) writeInactive(
+	w http.ResponseWriter,
+	r *http.Request,
+	redirectUrl string,
+	message string,
+	statusCode int,
+) {
+	if redirectUrl != "" {
+		http.Redirect(w, r, redirectUrl, http.StatusFound)
+		return
+	}
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	http.Error(w, message, statusCode)
+}
+
+var warningPage = template.Must(template.New("warning").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Link blocked</title></head>
+<body>
+<h1>This link has been blocked</h1>
+<p>The short url <code>/{{.ShortUrl}}</code> points to <code>{{.Host}}</code>,
+a destination blocked by the policy of this service.</p>
+</body>
+</html>
+`))
+
+// WarningPage explains that a short url was not followed because its
+// destination is blocked, the destination itself is never linked. Short urls
+// that are not blocked are not found, so the page never reveals where an
+// allowed link goes.
+func (u *UrlController) WarningPage(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "UrlController WarningPage")
+	defer span.End()
+
+	shortUrl := r.PathValue("shortUrl")
+	logger := zerolog.Ctx(c).
+		With().
+		Str(log.KeyProcess, "WarningPage").
+		Str(log.KeyShortUrl, shortUrl).
+		Logger()
+
+	logger.Info().Msgf("resolving shortUrl=%s", shortUrl)
+	c = logger.WithContext(c)
+	existed, err := u.service.ResolveUrl(c, shortUrl)
+	if !errors.Is(err, service.ErrUrlBlocked) {
+		err = fmt.Errorf(
+			"shortUrl=%s is not blocked with error=%w",
+			shortUrl,
+			apperrors.ErrNotFound,
 		)
+		logger.Info().Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf("resolved blocked shortUrl=%s", shortUrl)
+
+	host := ""
+	destination, err := url.Parse(existed.Url)
+	if err == nil {
+		host = destination.Hostname()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	err = warningPage.Execute(w, map[string]string{"ShortUrl": shortUrl, "Host": host})
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msgf("failed writing warning page of shortUrl=%s with error=%s", shortUrl, err.Error())
+	}
+}
+
+func (u *UrlController) GetUrlMetadata(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "UrlController GetUrlMetadata")
+	defer span.End()
+
+	shortUrl := r.PathValue("shortUrl")
+	logger := zerolog.Ctx(c).
+		With().
+		Str(log.KeyProcess, "GetUrlMetadata").
+		Str(log.KeyShortUrl, shortUrl).
+		Logger()
+
+	if !requireRole(c, w, auth.RoleViewer) {
+		return
+	}
+
+	logger.Info().Msgf("finding shortUrl=%s", shortUrl)
+	c = logger.WithContext(c)
+	existed, err := u.service.GetUrlMetadata(c, shortUrl)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msgf("failed finding shortUrl=%s with error=%s", shortUrl, err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
 		return
 	}
 	logger.Info().
@@ -275,7 +504,6 @@ func (u *UrlController) GetUrlByShortUrl(w http.ResponseWriter, r *http.Request)
 		},
 		http.StatusOK,
 	)
-	http.Redirect(w, r, existed.Url, http.StatusTemporaryRedirect)
 }
 
 func (u *UrlController) GetUrlByShortUrlDetail(w http.ResponseWriter, r *http.Request) {
@@ -290,23 +518,30 @@ func (u *UrlController) GetUrlByShortUrlDetail(w http.ResponseWriter, r *http.Re
 		Str(log.KeyShortUrl, shortUrl).
 		Logger()
 
-	logger.Info().Msgf("finding shortUrl=%s", shortUrl)
+	if !requireRole(c, w, auth.RoleViewer) {
+		return
+	}
+
+	logger.Info().Msg("parsing stats query")
+	param, err := parseStatsParams(r.URL.Query())
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msg("parsed stats query")
+
+	logger.Info().Msgf("finding stats of shortUrl=%s", shortUrl)
 	c = logger.WithContext(c)
-	existed, err := u.service.GetUrlByShortUrlDetail(c, shortUrl)
+	stats, err := u.service.GetUrlStats(c, shortUrl, param)
 	if err != nil {
 		logger.Error().
 			Err(err).
-			Msgf("failed finding shortUrl=%s with error=%s", shortUrl, err.Error())
-		response.WriteJsonResponse(
-			c,
-			w,
-			map[string]string{},
-			map[string]interface{}{},
-			http.StatusBadRequest,
-		)
+			Msgf("failed finding stats of shortUrl=%s with error=%s", shortUrl, err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
 		return
 	}
-	logger.Info().Msgf("found url=%s shortUrl=%s", existed.Url, existed.ShortUrl)
+	logger.Info().Msgf("found stats of shortUrl=%s", shortUrl)
 
 	response.WriteJsonResponse(
 		c,
@@ -314,9 +549,160 @@ func (u *UrlController) GetUrlByShortUrlDetail(w http.ResponseWriter, r *http.Re
 		map[string]string{},
 		map[string]interface{}{
 			"status":  "success",
-			"message": fmt.Sprintf("found url=%s to shortUrl=%s", existed.Url, existed.ShortUrl),
-			"data":    existed,
+			"message": fmt.Sprintf("found stats of shortUrl=%s", shortUrl),
+			"data":    stats,
 		},
 		http.StatusOK,
 	)
 }
+
+func (u *UrlController) ListUrls(w http.ResponseWriter, r *http.Request) {
+	c, span := tracer.Start(r.Context(), "UrlController ListUrls")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).
+		With().
+		Str(log.KeyProcess, "ListUrls").
+		Logger()
+
+	if !requireRole(c, w, auth.RoleViewer) {
+		return
+	}
+
+	logger.Info().Msg("parsing list query")
+	param, err := parseListParams(r.URL.Query())
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msg("parsed list query")
+
+	logger.Info().Msg("listing urls")
+	c = logger.WithContext(c)
+	page, err := u.service.ListUrls(c, param)
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed listing urls with error=%s", err.Error())
+		response.WriteProblem(c, w, map[string]string{}, err)
+		return
+	}
+	logger.Info().Msgf("listed %d urls", len(page.Urls))
+
+	response.WriteJsonResponse(
+		c,
+		w,
+		map[string]string{},
+		map[string]interface{}{
+			"status":  "success",
+			"message": fmt.Sprintf("found %d urls", len(page.Urls)),
+			"data":    page,
+		},
+		http.StatusOK,
+	)
+}
+
+// parseListParams reads owner_id, the created and updated ranges as RFC3339,
+// host, q, sort, cursor and limit from the query, missing values are
+// defaulted by the service.
+func parseListParams(query url.Values) (service.ListUrlsParams, error) {
+	param := service.ListUrlsParams{
+		Host:   query.Get("host"),
+		Search: query.Get("q"),
+		Sort:   query.Get("sort"),
+		Cursor: query.Get("cursor"),
+	}
+
+	if ownerID := query.Get("owner_id"); ownerID != "" {
+		parsed, err := uuid.Parse(ownerID)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing owner_id=%s with error=%w: %w",
+				ownerID,
+				service.ErrInvalidListParams,
+				err,
+			)
+		}
+		param.OwnerID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+
+	times := map[string]**time.Time{
+		"created_from": &param.CreatedFrom,
+		"created_to":   &param.CreatedTo,
+		"updated_from": &param.UpdatedFrom,
+		"updated_to":   &param.UpdatedTo,
+	}
+	for key, target := range times {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing %s=%s with error=%w: %w",
+				key,
+				value,
+				service.ErrInvalidListParams,
+				err,
+			)
+		}
+		*target = &parsed
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing limit=%s with error=%w: %w",
+				limit,
+				service.ErrInvalidListParams,
+				err,
+			)
+		}
+		param.Limit = int32(parsed)
+	}
+	return param, nil
+}
+
+// parseStatsParams reads from and to as RFC3339, interval and limit from the
+// query, missing values are defaulted by the service.
+func parseStatsParams(query url.Values) (service.StatsParams, error) {
+	param := service.StatsParams{Interval: query.Get("interval")}
+
+	var err error
+	if from := query.Get("from"); from != "" {
+		param.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing from=%s with error=%w: %w",
+				from,
+				service.ErrInvalidStatsParams,
+				err,
+			)
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		param.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing to=%s with error=%w: %w",
+				to,
+				service.ErrInvalidStatsParams,
+				err,
+			)
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil {
+			return param, fmt.Errorf(
+				"failed parsing limit=%s with error=%w: %w",
+				limit,
+				service.ErrInvalidStatsParams,
+				err,
+			)
+		}
+		param.Limit = int32(parsed)
+	}
+	return param, nil
+}
@@ -1,19 +1,125 @@
 package errors
 
-type InternalServerError struct {
-	Message    string
-	StatusCode int
+import (
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"net"
+	"net/http"
+)
+
+// Kind is the class of a failure, it decides the http status it is reported
+// with.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthenticated
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindGone
+	KindRateLimited
+	KindUnavailable
+)
+
+func (k Kind) StatusCode() int {
+	switch k {
+	case KindInvalid:
+		return http.StatusBadRequest
+	case KindUnauthenticated:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindGone:
+		return http.StatusGone
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is a failure with a stable Code clients can match on, the errors of
+// the service are declared as *Error and wrapped with the details of the
+// failure.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
 }
 
-func (h *InternalServerError) Error() string {
-	return h.Message
+func (e *Error) Error() string {
+	return e.Message
 }
 
-type BadRequestError struct {
-	Message    string
-	StatusCode int
+// Extender is implemented by errors that add members to their problem
+// response.
+type Extender interface {
+	Extensions() map[string]interface{}
 }
 
-func (h *BadRequestError) Error() string {
-	return h.Message
+var (
+	ErrInternal    = New(KindInternal, "internal", "internal error")
+	ErrNotFound    = New(KindNotFound, "not_found", "not found")
+	ErrUnavailable = New(KindUnavailable, "unavailable", "dependency unavailable")
+)
+
+// Detailer is implemented by errors that describe the failure to clients
+// more precisely than the message of their *Error.
+type Detailer interface {
+	Detail() string
+}
+
+// Detail is the message of err for clients, the message of the *Error err is
+// classified as or the Detail of a Detailer in its chain. The rest of the
+// chain only goes to the logs, internal and unavailable errors are not
+// detailed.
+func Detail(err error) string {
+	classified := Classify(err)
+	switch classified.Kind {
+	case KindInternal:
+		return "the request failed unexpectedly, retry later or report the hashcode"
+	case KindUnavailable:
+		return "a dependency of the service is unavailable, retry later"
+	}
+
+	detailer := Detailer(nil)
+	if stderrors.As(err, &detailer) {
+		return detailer.Detail()
+	}
+	return classified.Message
+}
+
+// Classify returns the *Error wrapped by err, rows that are not found are
+// ErrNotFound, failing connections to postgres or redis are ErrUnavailable
+// and everything else is ErrInternal.
+func Classify(err error) *Error {
+	classified := &Error{}
+	if stderrors.As(err, &classified) {
+		return classified
+	}
+
+	netErr := net.Error(nil)
+	switch {
+	case stderrors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case stderrors.As(err, &netErr),
+		stderrors.Is(err, driver.ErrBadConn),
+		stderrors.Is(err, sql.ErrConnDone):
+		return ErrUnavailable
+	default:
+		return ErrInternal
+	}
 }
@@ -0,0 +1,24 @@
+package generator
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+func encodeBase(n uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	if n == 0 {
+		return string(alphabet[0])
+	}
+
+	encoded := []byte{}
+	for n > 0 {
+		encoded = append(encoded, alphabet[n%base])
+		n /= base
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
+
+func EncodeBase62(n uint64) string {
+	return encodeBase(n, base62Alphabet)
+}
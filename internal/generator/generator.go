@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Alturino/url-shortener/internal/config"
+)
+
+const (
+	GeneratorRandom   = "random"
+	GeneratorSequence = "sequence"
+	GeneratorHashids  = "hashids"
+)
+
+type ShortCodeGenerator interface {
+	Generate(c context.Context) (string, error)
+}
+
+type Sequence interface {
+	NextShortUrlSequence(c context.Context) (int64, error)
+}
+
+func NewShortCodeGenerator(
+	config config.ShortCode,
+	sequence Sequence,
+) (ShortCodeGenerator, error) {
+	switch config.Generator {
+	case GeneratorRandom, "":
+		return NewRandomGenerator(config.Length)
+	case GeneratorSequence:
+		return NewSequenceGenerator(sequence), nil
+	case GeneratorHashids:
+		return NewHashidsGenerator(config.Salt, config.Length, sequence)
+	default:
+		return nil, fmt.Errorf("unknown short code generator=%s", config.Generator)
+	}
+}
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// hashidsSeparators are never used to encode a number, so the first separator
+// in a code marks where the padding starts and codes stay unique.
+const hashidsSeparators = "cfhistuCFHISTU"
+
+type HashidsGenerator struct {
+	alphabet  string
+	salt      string
+	minLength int
+	sequence  Sequence
+}
+
+func NewHashidsGenerator(
+	salt string,
+	minLength int,
+	sequence Sequence,
+) (*HashidsGenerator, error) {
+	if salt == "" {
+		return nil, fmt.Errorf("hashids generator requires a non empty salt")
+	}
+
+	alphabet := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(hashidsSeparators, r) {
+			return -1
+		}
+		return r
+	}, base62Alphabet)
+
+	return &HashidsGenerator{
+		alphabet:  consistentShuffle(alphabet, salt),
+		salt:      salt,
+		minLength: minLength,
+		sequence:  sequence,
+	}, nil
+}
+
+func (g *HashidsGenerator) Generate(c context.Context) (string, error) {
+	n, err := g.sequence.NextShortUrlSequence(c)
+	if err != nil {
+		return "", fmt.Errorf("failed getting next short_url sequence with error=%w", err)
+	}
+	return g.Encode(uint64(n)), nil
+}
+
+func (g *HashidsGenerator) Encode(n uint64) string {
+	lottery := g.alphabet[n%uint64(len(g.alphabet))]
+	alphabet := consistentShuffle(g.alphabet, string(lottery)+g.salt)
+
+	encoded := string(lottery) + encodeBase(n, alphabet)
+	if len(encoded) >= g.minLength {
+		return encoded
+	}
+
+	padding := consistentShuffle(alphabet, alphabet)
+	builder := strings.Builder{}
+	builder.WriteString(encoded)
+	builder.WriteByte(hashidsSeparators[n%uint64(len(hashidsSeparators))])
+	for i := 0; builder.Len() < g.minLength; i++ {
+		builder.WriteByte(padding[i%len(padding)])
+	}
+	return builder.String()
+}
+
+func consistentShuffle(alphabet string, salt string) string {
+	if salt == "" {
+		return alphabet
+	}
+	shuffled := []byte(alphabet)
+	for i, v, p := len(shuffled)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		integer := int(salt[v])
+		p += integer
+		j := (integer + v + p) % i
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		v++
+	}
+	return string(shuffled)
+}
@@ -0,0 +1,32 @@
+package generator
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+type RandomGenerator struct {
+	length int
+}
+
+func NewRandomGenerator(length int) (*RandomGenerator, error) {
+	if length <= 0 {
+		return nil, fmt.Errorf("short code length=%d must be greater than 0", length)
+	}
+	return &RandomGenerator{length: length}, nil
+}
+
+func (g *RandomGenerator) Generate(c context.Context) (string, error) {
+	base := big.NewInt(int64(len(base62Alphabet)))
+	code := make([]byte, g.length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, base)
+		if err != nil {
+			return "", fmt.Errorf("failed generating random short code with error=%w", err)
+		}
+		code[i] = base62Alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,22 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+)
+
+type SequenceGenerator struct {
+	sequence Sequence
+}
+
+func NewSequenceGenerator(sequence Sequence) *SequenceGenerator {
+	return &SequenceGenerator{sequence: sequence}
+}
+
+func (g *SequenceGenerator) Generate(c context.Context) (string, error) {
+	n, err := g.sequence.NextShortUrlSequence(c)
+	if err != nil {
+		return "", fmt.Errorf("failed getting next short_url sequence with error=%w", err)
+	}
+	return EncodeBase62(uint64(n)), nil
+}
@@ -28,6 +28,11 @@ const (
 	KeyRequestURL         = "requestURL"
 	KeyShortUrl           = "shortUrl"
 	KeyConfig             = "config"
+	KeyAlias              = "alias"
+	KeyRedirectStatus     = "redirectStatus"
+	KeyShared             = "shared"
+	KeyOwnerID            = "ownerId"
+	KeyApiKeyID           = "apiKeyId"
 )
 
 type hashcode struct{}
@@ -36,6 +41,13 @@ func HashcodeFromContext(c context.Context) string {
 	return c.Value(hashcode{}).(string)
 }
 
+// LookupHashcode is HashcodeFromContext for contexts that may not belong to a
+// request.
+func LookupHashcode(c context.Context) (string, bool) {
+	h, ok := c.Value(hashcode{}).(string)
+	return h, ok
+}
+
 func AttachHashcodeToContext(c context.Context, h string) context.Context {
 	return context.WithValue(c, hashcode{}, h)
 }
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/response"
+)
+
+type Authenticator interface {
+	Authenticate(c context.Context, token string) (auth.Principal, error)
+}
+
+// Authenticate resolves the bearer token of the request to an auth.Principal,
+// requests without an Authorization header continue anonymously and the
+// handlers decide whether they need a principal.
+func Authenticate(authenticator Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, span := tracer.Start(r.Context(), "middleware Authenticate")
+			defer span.End()
+
+			logger := zerolog.Ctx(c).With().Logger()
+
+			authorization := r.Header.Get("Authorization")
+			if authorization == "" {
+				next.ServeHTTP(w, r.WithContext(c))
+				return
+			}
+
+			logger.Info().Msg("authenticating bearer token")
+			scheme, token, found := strings.Cut(authorization, " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				err := fmt.Errorf(
+					"authorization header must be a bearer token with error=%w",
+					auth.ErrInvalidCredentials,
+				)
+				logger.Error().Err(err).Msg("authorization header is not a bearer token")
+				response.WriteProblem(c, w, map[string]string{}, err)
+				return
+			}
+
+			principal, err := authenticator.Authenticate(c, strings.TrimSpace(token))
+			if err != nil {
+				logger.Error().Err(err).Msg("failed authenticating bearer token")
+				response.WriteProblem(c, w, map[string]string{}, err)
+				return
+			}
+			logger.Info().
+				Str(log.KeyOwnerID, principal.OwnerID.String()).
+				Msg("authenticated bearer token")
+
+			c = auth.AttachPrincipalToContext(c, principal)
+			next.ServeHTTP(w, r.WithContext(c))
+		})
+	}
+}
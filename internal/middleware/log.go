@@ -7,16 +7,23 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/request"
 )
 
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		hashcode := uuid.NewString()
 
+		header := r.Header
+		if header.Get("Authorization") != "" {
+			header = header.Clone()
+			header.Set("Authorization", "[REDACTED]")
+		}
+
 		logger := zerolog.Ctx(r.Context())
 		logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
 			return c.Str(log.KeyHashcode, hashcode).
-				Any(log.KeyRequestHeader, r.Header).
+				Any(log.KeyRequestHeader, header).
 				Str(log.KeyRequestHost, r.Host).
 				Str(log.KeyRequestIp, r.RemoteAddr).
 				Str(log.KeyRequestMethod, r.Method).
@@ -27,6 +34,7 @@ func Logging(next http.Handler) http.Handler {
 
 		logger.Info().Msg("attaching request value to context")
 		c := log.AttachHashcodeToContext(r.Context(), hashcode)
+		c = request.AttachClientIPToContext(c, request.ClientIP(r))
 		c = logger.WithContext(c)
 		newR := r.WithContext(c)
 		logger.Info().Msg("attached request value to context")
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/cache"
+	"github.com/Alturino/url-shortener/internal/config"
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
+	"github.com/Alturino/url-shortener/internal/request"
+	"github.com/Alturino/url-shortener/internal/response"
+)
+
+var ErrRateLimited = apperrors.New(
+	apperrors.KindRateLimited,
+	"rate_limited",
+	"rate limit exceeded",
+)
+
+const (
+	RouteClassCreate   = "create"
+	RouteClassRedirect = "redirect"
+	RouteClassStats    = "stats"
+)
+
+// RateLimit limits the requests of each route class per caller, callers are
+// identified by their principal and anonymous callers by their client ip, so
+// it has to run after Authenticate. Requests are let through when redis is
+// unavailable.
+func RateLimit(limiter *cache.RateLimiter, rateLimitConfig config.RateLimit) Middleware {
+	rules := map[string]config.RateLimitRule{
+		RouteClassCreate:   rateLimitConfig.Create,
+		RouteClassRedirect: rateLimitConfig.Redirect,
+		RouteClassStats:    rateLimitConfig.Stats,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class := routeClass(r)
+			rule, ok := rules[class]
+			if !ok || rule.Limit <= 0 || rule.Window <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			c, span := tracer.Start(r.Context(), "middleware RateLimit")
+			logger := zerolog.Ctx(c).With().Logger()
+
+			identity := "ip:" + request.ClientIP(r)
+			if principal, ok := auth.PrincipalFromContext(c); ok {
+				identity = "owner:" + principal.OwnerID.String()
+			}
+
+			result, err := limiter.Allow(c, class+":"+identity, rule.Limit, rule.Window)
+			span.End()
+			if err != nil {
+				logger.Error().
+					Err(err).
+					Msgf("failed rate limiting class=%s with error=%s", class, err.Error())
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", rule.Limit, seconds(rule.Window)))
+			header.Set("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+			header.Set("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+			header.Set("RateLimit-Reset", strconv.FormatInt(seconds(result.Reset), 10))
+			if result.Allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			logger.Warn().Msgf("rate limit of class=%s exceeded by %s", class, identity)
+			response.WriteProblem(
+				c,
+				w,
+				map[string]string{"Retry-After": strconv.FormatInt(seconds(result.RetryAfter), 10)},
+				fmt.Errorf("rate limit of class=%s exceeded with error=%w", class, ErrRateLimited),
+			)
+		})
+	}
+}
+
+// routeClass maps r to the route class its limit is configured for, listing
+// the urls shares the stats limit and routes without a class are not limited.
+func routeClass(r *http.Request) string {
+	path := strings.Trim(r.URL.Path, "/")
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+	switch {
+	case r.Method == http.MethodPost && (path == "urls" || path == "urls/batch"):
+		return RouteClassCreate
+	case read && strings.HasPrefix(path, "urls/") && strings.HasSuffix(path, "/stats"),
+		read && path == "urls":
+		return RouteClassStats
+	case read && path != "" && path != "urls" && !strings.Contains(path, "/"):
+		return RouteClassRedirect
+	default:
+		return ""
+	}
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP rewrites r.RemoteAddr with the client address from X-Forwarded-For or
+// X-Real-IP, only add it to the stack when running behind a trusted proxy.
+// Each of the trustedHops proxies in front of the server appends the address
+// it received the request from to X-Forwarded-For, so the client address is
+// the trustedHops-th entry from the right. The entries left of it are sent by
+// the client and are ignored.
+func RealIP(trustedHops int) Middleware {
+	if trustedHops < 1 {
+		trustedHops = 1
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := ""
+			if forwardedFor := r.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+				entries := strings.Split(strings.Join(forwardedFor, ","), ",")
+				if len(entries) >= trustedHops {
+					ip = strings.TrimSpace(entries[len(entries)-trustedHops])
+				}
+			} else {
+				ip = strings.TrimSpace(r.Header.Get("X-Real-IP"))
+			}
+
+			if net.ParseIP(ip) != nil {
+				r.RemoteAddr = net.JoinHostPort(ip, "0")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Alturino/url-shortener/internal/config"
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/validation"
+)
+
+const (
+	name = "github.com/Alturino/url-shortener"
+
+	defaultReloadInterval = 30 * time.Second
+)
+
+var tracer = otel.Tracer(name)
+
+var ErrBlocked = apperrors.New(
+	apperrors.KindInvalid,
+	"destination_blocked",
+	"destination blocked by policy",
+)
+
+// BlockedError is the rule that blocked a destination host.
+type BlockedError struct {
+	Host string
+	Rule string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("host=%s is blocked by rule=%s", e.Host, e.Rule)
+}
+
+func (e *BlockedError) Unwrap() error {
+	return ErrBlocked
+}
+
+func (e *BlockedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"reason": validation.ReasonBlocked}
+}
+
+// Engine checks destination hosts against the rules file, the file is polled
+// every interval and reloaded when it changes, a file that fails to load keeps
+// the previous rules in place.
+type Engine struct {
+	path     string
+	interval time.Duration
+	rules    atomic.Pointer[ruleSet]
+
+	modTime time.Time
+	size    int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewEngine(config config.Policy) *Engine {
+	interval := config.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	return &Engine{
+		path:     config.RulesFile,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Check returns a *BlockedError wrapping ErrBlocked when host matches a block
+// rule or misses every allow rule of an allowlist.
+func (e *Engine) Check(host string) error {
+	rules := e.rules.Load()
+	if rules == nil {
+		return nil
+	}
+
+	host = normalizeHost(host)
+	if rule, ok := rules.block.match(host); ok {
+		return &BlockedError{Host: host, Rule: rule}
+	}
+	if rules.allow.empty() {
+		return nil
+	}
+	if _, ok := rules.allow.match(host); !ok {
+		return &BlockedError{Host: host, Rule: "allowlist"}
+	}
+	return nil
+}
+
+// Load reads and compiles the rules file and replaces the current rules.
+func (e *Engine) Load(c context.Context) error {
+	c, span := tracer.Start(c, "Engine Load")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msgf("loading policy rules from file=%s", e.path)
+	info, err := os.Stat(e.path)
+	if err != nil {
+		err = fmt.Errorf("failed reading policy rules file=%s with error=%w", e.path, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+	content, err := os.ReadFile(e.path)
+	if err != nil {
+		err = fmt.Errorf("failed reading policy rules file=%s with error=%w", e.path, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+
+	file := File{}
+	err = yaml.Unmarshal(content, &file)
+	if err != nil {
+		err = fmt.Errorf("failed parsing policy rules file=%s with error=%w", e.path, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+	rules, err := compile(file)
+	if err != nil {
+		err = fmt.Errorf("failed compiling policy rules file=%s with error=%w", e.path, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+
+	e.rules.Store(rules)
+	e.modTime, e.size = info.ModTime(), info.Size()
+	logger.Info().Msgf("loaded policy rules from file=%s", e.path)
+
+	return nil
+}
+
+// Start reloads the rules file every interval when it changed until Shutdown
+// is called.
+func (e *Engine) Start(c context.Context) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "PolicyEngine").Logger()
+	c = logger.WithContext(context.WithoutCancel(c))
+
+	go func() {
+		defer close(e.done)
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				e.reload(c)
+			case <-e.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the reload loop.
+func (e *Engine) Shutdown(c context.Context) error {
+	close(e.stop)
+	select {
+	case <-e.done:
+		return nil
+	case <-c.Done():
+		return fmt.Errorf("failed stopping policy engine with error=%w", c.Err())
+	}
+}
+
+func (e *Engine) reload(c context.Context) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		err = fmt.Errorf("failed reading policy rules file=%s with error=%w", e.path, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return
+	}
+	if info.ModTime().Equal(e.modTime) && info.Size() == e.size {
+		return
+	}
+
+	logger.Info().Msgf("policy rules file=%s changed reloading", e.path)
+	_ = e.Load(c)
+}
@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Rules are the host matchers of one list of the rules file, suffix matches
+// the host and its subdomains unless it starts with a dot, then it only
+// matches the subdomains.
+type Rules struct {
+	Exact  []string `yaml:"exact"`
+	Suffix []string `yaml:"suffix"`
+	Regex  []string `yaml:"regex"`
+}
+
+// File is the content of the rules file, block always applies and a non
+// empty allow turns the engine into an allowlist.
+type File struct {
+	Block Rules `yaml:"block"`
+	Allow Rules `yaml:"allow"`
+}
+
+type matcher struct {
+	exact  map[string]struct{}
+	suffix []string
+	regex  []*regexp.Regexp
+}
+
+func newMatcher(rules Rules) (matcher, error) {
+	m := matcher{exact: make(map[string]struct{}, len(rules.Exact))}
+	for _, host := range rules.Exact {
+		normalized, err := normalizeRuleHost(host)
+		if err != nil {
+			return matcher{}, err
+		}
+		m.exact[normalized] = struct{}{}
+	}
+	for _, suffix := range rules.Suffix {
+		normalized, err := normalizeRuleHost(suffix)
+		if err != nil {
+			return matcher{}, err
+		}
+		m.suffix = append(m.suffix, normalized)
+	}
+	for _, pattern := range rules.Regex {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return matcher{}, fmt.Errorf("failed compiling regex=%s with error=%w", pattern, err)
+		}
+		m.regex = append(m.regex, compiled)
+	}
+	return m, nil
+}
+
+func (m matcher) empty() bool {
+	return len(m.exact) == 0 && len(m.suffix) == 0 && len(m.regex) == 0
+}
+
+// match returns the rule matching host or false.
+func (m matcher) match(host string) (string, bool) {
+	if _, ok := m.exact[host]; ok {
+		return "exact:" + host, true
+	}
+	for _, suffix := range m.suffix {
+		if strings.HasPrefix(suffix, ".") {
+			if strings.HasSuffix(host, suffix) {
+				return "suffix:" + suffix, true
+			}
+			continue
+		}
+		if host == suffix || strings.HasSuffix(host, "."+suffix) {
+			return "suffix:" + suffix, true
+		}
+	}
+	for _, pattern := range m.regex {
+		if pattern.MatchString(host) {
+			return "regex:" + pattern.String(), true
+		}
+	}
+	return "", false
+}
+
+type ruleSet struct {
+	block matcher
+	allow matcher
+}
+
+func compile(file File) (*ruleSet, error) {
+	block, err := newMatcher(file.Block)
+	if err != nil {
+		return nil, fmt.Errorf("failed compiling block rules with error=%w", err)
+	}
+	allow, err := newMatcher(file.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("failed compiling allow rules with error=%w", err)
+	}
+	return &ruleSet{block: block, allow: allow}, nil
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// normalizeRuleHost normalizes a host of an exact or suffix rule and converts
+// it to punycode like the destination hosts it is matched against, a suffix
+// keeps its leading dot.
+func normalizeRuleHost(host string) (string, error) {
+	normalized := normalizeHost(host)
+	if net.ParseIP(normalized) != nil {
+		return normalized, nil
+	}
+
+	dot := ""
+	if strings.HasPrefix(normalized, ".") {
+		dot, normalized = ".", normalized[1:]
+	}
+	ascii, err := idna.Lookup.ToASCII(normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed converting host=%s to punycode with error=%w", host, err)
+	}
+	return dot + ascii, nil
+}
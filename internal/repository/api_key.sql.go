@@ -0,0 +1,109 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: api_key.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const findApiKeyByKeyHash = `-- name: FindApiKeyByKeyHash :one
+select id, owner_id, name, key_hash, created_at, revoked_at, role from api_keys where key_hash = $1 and revoked_at is null
+`
+
+func (q *Queries) FindApiKeyByKeyHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.queryRow(ctx, q.findApiKeyByKeyHashStmt, findApiKeyByKeyHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.RevokedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const insertApiKey = `-- name: InsertApiKey :one
+insert into api_keys(id, owner_id, name, key_hash, role)
+values($1, $2, $3, $4, $5) returning id, owner_id, name, key_hash, created_at, revoked_at, role
+`
+
+type InsertApiKeyParams struct {
+	ID      uuid.UUID `json:"id"`
+	OwnerID uuid.UUID `json:"owner_id"`
+	Name    string    `json:"name"`
+	KeyHash string    `json:"key_hash"`
+	Role    string    `json:"role"`
+}
+
+func (q *Queries) InsertApiKey(ctx context.Context, arg InsertApiKeyParams) (ApiKey, error) {
+	row := q.queryRow(ctx, q.insertApiKeyStmt, insertApiKey,
+		arg.ID,
+		arg.OwnerID,
+		arg.Name,
+		arg.KeyHash,
+		arg.Role,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.RevokedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const insertApiKeyIfNotExists = `-- name: InsertApiKeyIfNotExists :exec
+insert into api_keys(id, owner_id, name, key_hash, role)
+values($1, $2, $3, $4, $5)
+on conflict (key_hash) do nothing
+`
+
+type InsertApiKeyIfNotExistsParams struct {
+	ID      uuid.UUID `json:"id"`
+	OwnerID uuid.UUID `json:"owner_id"`
+	Name    string    `json:"name"`
+	KeyHash string    `json:"key_hash"`
+	Role    string    `json:"role"`
+}
+
+func (q *Queries) InsertApiKeyIfNotExists(ctx context.Context, arg InsertApiKeyIfNotExistsParams) error {
+	_, err := q.exec(ctx, q.insertApiKeyIfNotExistsStmt, insertApiKeyIfNotExists,
+		arg.ID,
+		arg.OwnerID,
+		arg.Name,
+		arg.KeyHash,
+		arg.Role,
+	)
+	return err
+}
+
+const revokeApiKey = `-- name: RevokeApiKey :one
+update api_keys set revoked_at = now() where id = $1 and revoked_at is null returning id, owner_id, name, key_hash, created_at, revoked_at, role
+`
+
+func (q *Queries) RevokeApiKey(ctx context.Context, id uuid.UUID) (ApiKey, error) {
+	row := q.queryRow(ctx, q.revokeApiKeyStmt, revokeApiKey, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.RevokedAt,
+		&i.Role,
+	)
+	return i, err
+}
@@ -0,0 +1,118 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: audit_log.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const insertAuditLog = `-- name: InsertAuditLog :exec
+insert into audit_logs (actor_id, actor_role, action, short_url, before, after, client_ip, hashcode)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type InsertAuditLogParams struct {
+	ActorID   uuid.NullUUID   `json:"actor_id"`
+	ActorRole string          `json:"actor_role"`
+	Action    string          `json:"action"`
+	ShortUrl  string          `json:"short_url"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	ClientIp  string          `json:"client_ip"`
+	Hashcode  string          `json:"hashcode"`
+}
+
+func (q *Queries) InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) error {
+	_, err := q.exec(ctx, q.insertAuditLogStmt, insertAuditLog,
+		arg.ActorID,
+		arg.ActorRole,
+		arg.Action,
+		arg.ShortUrl,
+		arg.Before,
+		arg.After,
+		arg.ClientIp,
+		arg.Hashcode,
+	)
+	return err
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+select id, actor_id, actor_role, action, short_url, before, after, client_ip, hashcode, created_at from audit_logs
+where
+    ($1::uuid is null or actor_id = $1::uuid)
+    and ($2::text is null or action = $2::text)
+    and ($3::text is null or short_url = $3::text)
+    and ($4::text is null or hashcode = $4::text)
+    and (
+        $5::timestamptz is null
+        or created_at >= $5::timestamptz
+    )
+    and (
+        $6::timestamptz is null
+        or created_at < $6::timestamptz
+    )
+    and ($7::bigint is null or id < $7::bigint)
+order by id desc
+limit $8
+`
+
+type ListAuditLogsParams struct {
+	ActorID     uuid.NullUUID  `json:"actor_id"`
+	Action      sql.NullString `json:"action"`
+	ShortUrl    sql.NullString `json:"short_url"`
+	Hashcode    sql.NullString `json:"hashcode"`
+	CreatedFrom sql.NullTime   `json:"created_from"`
+	CreatedTo   sql.NullTime   `json:"created_to"`
+	CursorID    sql.NullInt64  `json:"cursor_id"`
+	RowLimit    int32          `json:"row_limit"`
+}
+
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.query(ctx, q.listAuditLogsStmt, listAuditLogs,
+		arg.ActorID,
+		arg.Action,
+		arg.ShortUrl,
+		arg.Hashcode,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.ActorRole,
+			&i.Action,
+			&i.ShortUrl,
+			&i.Before,
+			&i.After,
+			&i.ClientIp,
+			&i.Hashcode,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
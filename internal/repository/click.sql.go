@@ -0,0 +1,258 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: click.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const countClicks = `-- name: CountClicks :one
+select
+    count(*) as clicks,
+    count(distinct nullif(client_ip, '')) as unique_visitors
+from clicks
+where
+    url_id = $1
+    and clicked_at >= $2::timestamptz
+    and clicked_at < $3::timestamptz
+`
+
+type CountClicksParams struct {
+	UrlID     uuid.UUID `json:"url_id"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+}
+
+type CountClicksRow struct {
+	Clicks         int64 `json:"clicks"`
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+func (q *Queries) CountClicks(ctx context.Context, arg CountClicksParams) (CountClicksRow, error) {
+	row := q.queryRow(ctx, q.countClicksStmt, countClicks, arg.UrlID, arg.StartedAt, arg.EndedAt)
+	var i CountClicksRow
+	err := row.Scan(&i.Clicks, &i.UniqueVisitors)
+	return i, err
+}
+
+const countClicksByInterval = `-- name: CountClicksByInterval :many
+select
+    buckets.bucket::timestamptz as bucket,
+    count(clicks.id) as clicks
+from generate_series(
+    date_trunc($1::text, ($2::timestamptz)::timestamp),
+    ($3::timestamptz)::timestamp,
+    ('1 ' || $1::text)::interval
+) as buckets (bucket)
+left join clicks
+    on
+        clicks.url_id = $4
+        and date_trunc($1::text, clicks.clicked_at) = buckets.bucket
+        and clicks.clicked_at >= $2::timestamptz
+        and clicks.clicked_at < $3::timestamptz
+group by buckets.bucket
+order by buckets.bucket
+`
+
+type CountClicksByIntervalParams struct {
+	Interval  string    `json:"interval"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	UrlID     uuid.UUID `json:"url_id"`
+}
+
+type CountClicksByIntervalRow struct {
+	Bucket time.Time `json:"bucket"`
+	Clicks int64     `json:"clicks"`
+}
+
+func (q *Queries) CountClicksByInterval(ctx context.Context, arg CountClicksByIntervalParams) ([]CountClicksByIntervalRow, error) {
+	rows, err := q.query(ctx, q.countClicksByIntervalStmt, countClicksByInterval,
+		arg.Interval,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.UrlID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountClicksByIntervalRow
+	for rows.Next() {
+		var i CountClicksByIntervalRow
+		if err := rows.Scan(&i.Bucket, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTopReferrers = `-- name: FindTopReferrers :many
+select
+    referrer,
+    count(*) as clicks
+from clicks
+where
+    url_id = $1
+    and clicked_at >= $2::timestamptz
+    and clicked_at < $3::timestamptz
+group by referrer
+order by clicks desc, referrer asc
+limit $4
+`
+
+type FindTopReferrersParams struct {
+	UrlID     uuid.UUID `json:"url_id"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	TopLimit  int32     `json:"top_limit"`
+}
+
+type FindTopReferrersRow struct {
+	Referrer string `json:"referrer"`
+	Clicks   int64  `json:"clicks"`
+}
+
+func (q *Queries) FindTopReferrers(ctx context.Context, arg FindTopReferrersParams) ([]FindTopReferrersRow, error) {
+	rows, err := q.query(ctx, q.findTopReferrersStmt, findTopReferrers,
+		arg.UrlID,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.TopLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindTopReferrersRow
+	for rows.Next() {
+		var i FindTopReferrersRow
+		if err := rows.Scan(&i.Referrer, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTopUserAgentFamilies = `-- name: FindTopUserAgentFamilies :many
+select
+    user_agent_family,
+    count(*) as clicks
+from clicks
+where
+    url_id = $1
+    and clicked_at >= $2::timestamptz
+    and clicked_at < $3::timestamptz
+group by user_agent_family
+order by clicks desc, user_agent_family asc
+limit $4
+`
+
+type FindTopUserAgentFamiliesParams struct {
+	UrlID     uuid.UUID `json:"url_id"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	TopLimit  int32     `json:"top_limit"`
+}
+
+type FindTopUserAgentFamiliesRow struct {
+	UserAgentFamily string `json:"user_agent_family"`
+	Clicks          int64  `json:"clicks"`
+}
+
+func (q *Queries) FindTopUserAgentFamilies(ctx context.Context, arg FindTopUserAgentFamiliesParams) ([]FindTopUserAgentFamiliesRow, error) {
+	rows, err := q.query(ctx, q.findTopUserAgentFamiliesStmt, findTopUserAgentFamilies,
+		arg.UrlID,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.TopLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindTopUserAgentFamiliesRow
+	for rows.Next() {
+		var i FindTopUserAgentFamiliesRow
+		if err := rows.Scan(&i.UserAgentFamily, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertClicks = `-- name: InsertClicks :exec
+insert into clicks (
+    url_id, clicked_at, referrer, user_agent, user_agent_family, client_ip, accept_language
+)
+select
+    clicked.url_id,
+    clicked.clicked_at,
+    clicked.referrer,
+    clicked.user_agent,
+    clicked.user_agent_family,
+    clicked.client_ip,
+    clicked.accept_language
+from (
+    select
+        unnest($1::uuid[]) as url_id,
+        unnest($2::text[])::timestamptz as clicked_at,
+        unnest($3::text[]) as referrer,
+        unnest($4::text[]) as user_agent,
+        unnest($5::text[]) as user_agent_family,
+        unnest($6::text[]) as client_ip,
+        unnest($7::text[]) as accept_language
+) as clicked
+where exists (select 1 from urls where urls.id = clicked.url_id)
+`
+
+type InsertClicksParams struct {
+	UrlIds            []uuid.UUID `json:"url_ids"`
+	ClickedAts        []string    `json:"clicked_ats"`
+	Referrers         []string    `json:"referrers"`
+	UserAgents        []string    `json:"user_agents"`
+	UserAgentFamilies []string    `json:"user_agent_families"`
+	ClientIps         []string    `json:"client_ips"`
+	AcceptLanguages   []string    `json:"accept_languages"`
+}
+
+func (q *Queries) InsertClicks(ctx context.Context, arg InsertClicksParams) error {
+	_, err := q.exec(ctx, q.insertClicksStmt, insertClicks,
+		pq.Array(arg.UrlIds),
+		pq.Array(arg.ClickedAts),
+		pq.Array(arg.Referrers),
+		pq.Array(arg.UserAgents),
+		pq.Array(arg.UserAgentFamilies),
+		pq.Array(arg.ClientIps),
+		pq.Array(arg.AcceptLanguages),
+	)
+	return err
+}
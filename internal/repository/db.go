@@ -24,29 +24,157 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
-	if q.deleteUrlByShortUrlStmt, err = db.PrepareContext(ctx, deleteUrlByShortUrl); err != nil {
-		return nil, fmt.Errorf("error preparing query DeleteUrlByShortUrl: %w", err)
+	if q.archiveExpiredUrlsStmt, err = db.PrepareContext(ctx, archiveExpiredUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query ArchiveExpiredUrls: %w", err)
+	}
+	if q.countClicksStmt, err = db.PrepareContext(ctx, countClicks); err != nil {
+		return nil, fmt.Errorf("error preparing query CountClicks: %w", err)
+	}
+	if q.countClicksByIntervalStmt, err = db.PrepareContext(ctx, countClicksByInterval); err != nil {
+		return nil, fmt.Errorf("error preparing query CountClicksByInterval: %w", err)
+	}
+	if q.exportUrlsStmt, err = db.PrepareContext(ctx, exportUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query ExportUrls: %w", err)
+	}
+	if q.findApiKeyByKeyHashStmt, err = db.PrepareContext(ctx, findApiKeyByKeyHash); err != nil {
+		return nil, fmt.Errorf("error preparing query FindApiKeyByKeyHash: %w", err)
+	}
+	if q.findDailyUniqueVisitorsStmt, err = db.PrepareContext(ctx, findDailyUniqueVisitors); err != nil {
+		return nil, fmt.Errorf("error preparing query FindDailyUniqueVisitors: %w", err)
+	}
+	if q.findTopReferrersStmt, err = db.PrepareContext(ctx, findTopReferrers); err != nil {
+		return nil, fmt.Errorf("error preparing query FindTopReferrers: %w", err)
+	}
+	if q.findTopUserAgentFamiliesStmt, err = db.PrepareContext(ctx, findTopUserAgentFamilies); err != nil {
+		return nil, fmt.Errorf("error preparing query FindTopUserAgentFamilies: %w", err)
 	}
 	if q.findUrlByShortUrlStmt, err = db.PrepareContext(ctx, findUrlByShortUrl); err != nil {
 		return nil, fmt.Errorf("error preparing query FindUrlByShortUrl: %w", err)
 	}
+	if q.findUrlByShortUrlForUpdateStmt, err = db.PrepareContext(ctx, findUrlByShortUrlForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query FindUrlByShortUrlForUpdate: %w", err)
+	}
+	if q.findUrlRevisionStmt, err = db.PrepareContext(ctx, findUrlRevision); err != nil {
+		return nil, fmt.Errorf("error preparing query FindUrlRevision: %w", err)
+	}
+	if q.findUrlsByShortUrlsStmt, err = db.PrepareContext(ctx, findUrlsByShortUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query FindUrlsByShortUrls: %w", err)
+	}
+	if q.importUrlStmt, err = db.PrepareContext(ctx, importUrl); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportUrl: %w", err)
+	}
+	if q.importUrlIfNotExistsStmt, err = db.PrepareContext(ctx, importUrlIfNotExists); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportUrlIfNotExists: %w", err)
+	}
+	if q.importUrlOverwriteStmt, err = db.PrepareContext(ctx, importUrlOverwrite); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportUrlOverwrite: %w", err)
+	}
+	if q.incrementVisitedCountUrlsStmt, err = db.PrepareContext(ctx, incrementVisitedCountUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query IncrementVisitedCountUrls: %w", err)
+	}
+	if q.insertApiKeyStmt, err = db.PrepareContext(ctx, insertApiKey); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertApiKey: %w", err)
+	}
+	if q.insertApiKeyIfNotExistsStmt, err = db.PrepareContext(ctx, insertApiKeyIfNotExists); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertApiKeyIfNotExists: %w", err)
+	}
+	if q.insertAuditLogStmt, err = db.PrepareContext(ctx, insertAuditLog); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertAuditLog: %w", err)
+	}
+	if q.insertBaselineUrlRevisionStmt, err = db.PrepareContext(ctx, insertBaselineUrlRevision); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertBaselineUrlRevision: %w", err)
+	}
+	if q.insertClicksStmt, err = db.PrepareContext(ctx, insertClicks); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertClicks: %w", err)
+	}
 	if q.insertUrlStmt, err = db.PrepareContext(ctx, insertUrl); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertUrl: %w", err)
 	}
+	if q.insertUrlRevisionStmt, err = db.PrepareContext(ctx, insertUrlRevision); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertUrlRevision: %w", err)
+	}
+	if q.listAuditLogsStmt, err = db.PrepareContext(ctx, listAuditLogs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuditLogs: %w", err)
+	}
+	if q.listUrlRevisionsStmt, err = db.PrepareContext(ctx, listUrlRevisions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUrlRevisions: %w", err)
+	}
+	if q.listUrlsByCreatedAtStmt, err = db.PrepareContext(ctx, listUrlsByCreatedAt); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUrlsByCreatedAt: %w", err)
+	}
+	if q.listUrlsByVisitedCountStmt, err = db.PrepareContext(ctx, listUrlsByVisitedCount); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUrlsByVisitedCount: %w", err)
+	}
+	if q.nextShortUrlSequenceStmt, err = db.PrepareContext(ctx, nextShortUrlSequence); err != nil {
+		return nil, fmt.Errorf("error preparing query NextShortUrlSequence: %w", err)
+	}
+	if q.purgeDeletedUrlsStmt, err = db.PrepareContext(ctx, purgeDeletedUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeDeletedUrls: %w", err)
+	}
+	if q.restoreUrlByShortUrlStmt, err = db.PrepareContext(ctx, restoreUrlByShortUrl); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreUrlByShortUrl: %w", err)
+	}
+	if q.revokeApiKeyStmt, err = db.PrepareContext(ctx, revokeApiKey); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeApiKey: %w", err)
+	}
+	if q.softDeleteExpiredUrlsStmt, err = db.PrepareContext(ctx, softDeleteExpiredUrls); err != nil {
+		return nil, fmt.Errorf("error preparing query SoftDeleteExpiredUrls: %w", err)
+	}
+	if q.softDeleteUrlByShortUrlStmt, err = db.PrepareContext(ctx, softDeleteUrlByShortUrl); err != nil {
+		return nil, fmt.Errorf("error preparing query SoftDeleteUrlByShortUrl: %w", err)
+	}
 	if q.updateUrlStmt, err = db.PrepareContext(ctx, updateUrl); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateUrl: %w", err)
 	}
-	if q.updateVisitedCountUrlStmt, err = db.PrepareContext(ctx, updateVisitedCountUrl); err != nil {
-		return nil, fmt.Errorf("error preparing query UpdateVisitedCountUrl: %w", err)
+	if q.updateUrlDestinationStmt, err = db.PrepareContext(ctx, updateUrlDestination); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUrlDestination: %w", err)
+	}
+	if q.upsertDailyUniqueVisitorsStmt, err = db.PrepareContext(ctx, upsertDailyUniqueVisitors); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertDailyUniqueVisitors: %w", err)
 	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
-	if q.deleteUrlByShortUrlStmt != nil {
-		if cerr := q.deleteUrlByShortUrlStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteUrlByShortUrlStmt: %w", cerr)
+	if q.archiveExpiredUrlsStmt != nil {
+		if cerr := q.archiveExpiredUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing archiveExpiredUrlsStmt: %w", cerr)
+		}
+	}
+	if q.countClicksStmt != nil {
+		if cerr := q.countClicksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countClicksStmt: %w", cerr)
+		}
+	}
+	if q.countClicksByIntervalStmt != nil {
+		if cerr := q.countClicksByIntervalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countClicksByIntervalStmt: %w", cerr)
+		}
+	}
+	if q.exportUrlsStmt != nil {
+		if cerr := q.exportUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing exportUrlsStmt: %w", cerr)
+		}
+	}
+	if q.findApiKeyByKeyHashStmt != nil {
+		if cerr := q.findApiKeyByKeyHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findApiKeyByKeyHashStmt: %w", cerr)
+		}
+	}
+	if q.findDailyUniqueVisitorsStmt != nil {
+		if cerr := q.findDailyUniqueVisitorsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findDailyUniqueVisitorsStmt: %w", cerr)
+		}
+	}
+	if q.findTopReferrersStmt != nil {
+		if cerr := q.findTopReferrersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findTopReferrersStmt: %w", cerr)
+		}
+	}
+	if q.findTopUserAgentFamiliesStmt != nil {
+		if cerr := q.findTopUserAgentFamiliesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findTopUserAgentFamiliesStmt: %w", cerr)
 		}
 	}
 	if q.findUrlByShortUrlStmt != nil {
@@ -54,19 +182,139 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing findUrlByShortUrlStmt: %w", cerr)
 		}
 	}
+	if q.findUrlByShortUrlForUpdateStmt != nil {
+		if cerr := q.findUrlByShortUrlForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findUrlByShortUrlForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.findUrlRevisionStmt != nil {
+		if cerr := q.findUrlRevisionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findUrlRevisionStmt: %w", cerr)
+		}
+	}
+	if q.findUrlsByShortUrlsStmt != nil {
+		if cerr := q.findUrlsByShortUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findUrlsByShortUrlsStmt: %w", cerr)
+		}
+	}
+	if q.importUrlStmt != nil {
+		if cerr := q.importUrlStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importUrlStmt: %w", cerr)
+		}
+	}
+	if q.importUrlIfNotExistsStmt != nil {
+		if cerr := q.importUrlIfNotExistsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importUrlIfNotExistsStmt: %w", cerr)
+		}
+	}
+	if q.importUrlOverwriteStmt != nil {
+		if cerr := q.importUrlOverwriteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importUrlOverwriteStmt: %w", cerr)
+		}
+	}
+	if q.incrementVisitedCountUrlsStmt != nil {
+		if cerr := q.incrementVisitedCountUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing incrementVisitedCountUrlsStmt: %w", cerr)
+		}
+	}
+	if q.insertApiKeyStmt != nil {
+		if cerr := q.insertApiKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertApiKeyStmt: %w", cerr)
+		}
+	}
+	if q.insertApiKeyIfNotExistsStmt != nil {
+		if cerr := q.insertApiKeyIfNotExistsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertApiKeyIfNotExistsStmt: %w", cerr)
+		}
+	}
+	if q.insertAuditLogStmt != nil {
+		if cerr := q.insertAuditLogStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertAuditLogStmt: %w", cerr)
+		}
+	}
+	if q.insertBaselineUrlRevisionStmt != nil {
+		if cerr := q.insertBaselineUrlRevisionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertBaselineUrlRevisionStmt: %w", cerr)
+		}
+	}
+	if q.insertClicksStmt != nil {
+		if cerr := q.insertClicksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertClicksStmt: %w", cerr)
+		}
+	}
 	if q.insertUrlStmt != nil {
 		if cerr := q.insertUrlStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing insertUrlStmt: %w", cerr)
 		}
 	}
+	if q.insertUrlRevisionStmt != nil {
+		if cerr := q.insertUrlRevisionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertUrlRevisionStmt: %w", cerr)
+		}
+	}
+	if q.listAuditLogsStmt != nil {
+		if cerr := q.listAuditLogsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuditLogsStmt: %w", cerr)
+		}
+	}
+	if q.listUrlRevisionsStmt != nil {
+		if cerr := q.listUrlRevisionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUrlRevisionsStmt: %w", cerr)
+		}
+	}
+	if q.listUrlsByCreatedAtStmt != nil {
+		if cerr := q.listUrlsByCreatedAtStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUrlsByCreatedAtStmt: %w", cerr)
+		}
+	}
+	if q.listUrlsByVisitedCountStmt != nil {
+		if cerr := q.listUrlsByVisitedCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUrlsByVisitedCountStmt: %w", cerr)
+		}
+	}
+	if q.nextShortUrlSequenceStmt != nil {
+		if cerr := q.nextShortUrlSequenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing nextShortUrlSequenceStmt: %w", cerr)
+		}
+	}
+	if q.purgeDeletedUrlsStmt != nil {
+		if cerr := q.purgeDeletedUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeDeletedUrlsStmt: %w", cerr)
+		}
+	}
+	if q.restoreUrlByShortUrlStmt != nil {
+		if cerr := q.restoreUrlByShortUrlStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreUrlByShortUrlStmt: %w", cerr)
+		}
+	}
+	if q.revokeApiKeyStmt != nil {
+		if cerr := q.revokeApiKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeApiKeyStmt: %w", cerr)
+		}
+	}
+	if q.softDeleteExpiredUrlsStmt != nil {
+		if cerr := q.softDeleteExpiredUrlsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing softDeleteExpiredUrlsStmt: %w", cerr)
+		}
+	}
+	if q.softDeleteUrlByShortUrlStmt != nil {
+		if cerr := q.softDeleteUrlByShortUrlStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing softDeleteUrlByShortUrlStmt: %w", cerr)
+		}
+	}
 	if q.updateUrlStmt != nil {
 		if cerr := q.updateUrlStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateUrlStmt: %w", cerr)
 		}
 	}
-	if q.updateVisitedCountUrlStmt != nil {
-		if cerr := q.updateVisitedCountUrlStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing updateVisitedCountUrlStmt: %w", cerr)
+	if q.updateUrlDestinationStmt != nil {
+		if cerr := q.updateUrlDestinationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUrlDestinationStmt: %w", cerr)
+		}
+	}
+	if q.upsertDailyUniqueVisitorsStmt != nil {
+		if cerr := q.upsertDailyUniqueVisitorsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertDailyUniqueVisitorsStmt: %w", cerr)
 		}
 	}
 	return err
@@ -106,23 +354,85 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                        DBTX
-	tx                        *sql.Tx
-	deleteUrlByShortUrlStmt   *sql.Stmt
-	findUrlByShortUrlStmt     *sql.Stmt
-	insertUrlStmt             *sql.Stmt
-	updateUrlStmt             *sql.Stmt
-	updateVisitedCountUrlStmt *sql.Stmt
+	db                             DBTX
+	tx                             *sql.Tx
+	archiveExpiredUrlsStmt         *sql.Stmt
+	countClicksStmt                *sql.Stmt
+	countClicksByIntervalStmt      *sql.Stmt
+	exportUrlsStmt                 *sql.Stmt
+	findApiKeyByKeyHashStmt        *sql.Stmt
+	findDailyUniqueVisitorsStmt    *sql.Stmt
+	findTopReferrersStmt           *sql.Stmt
+	findTopUserAgentFamiliesStmt   *sql.Stmt
+	findUrlByShortUrlStmt          *sql.Stmt
+	findUrlByShortUrlForUpdateStmt *sql.Stmt
+	findUrlRevisionStmt            *sql.Stmt
+	findUrlsByShortUrlsStmt        *sql.Stmt
+	importUrlStmt                  *sql.Stmt
+	importUrlIfNotExistsStmt       *sql.Stmt
+	importUrlOverwriteStmt         *sql.Stmt
+	incrementVisitedCountUrlsStmt  *sql.Stmt
+	insertApiKeyStmt               *sql.Stmt
+	insertApiKeyIfNotExistsStmt    *sql.Stmt
+	insertAuditLogStmt             *sql.Stmt
+	insertBaselineUrlRevisionStmt  *sql.Stmt
+	insertClicksStmt               *sql.Stmt
+	insertUrlStmt                  *sql.Stmt
+	insertUrlRevisionStmt          *sql.Stmt
+	listAuditLogsStmt              *sql.Stmt
+	listUrlRevisionsStmt           *sql.Stmt
+	listUrlsByCreatedAtStmt        *sql.Stmt
+	listUrlsByVisitedCountStmt     *sql.Stmt
+	nextShortUrlSequenceStmt       *sql.Stmt
+	purgeDeletedUrlsStmt           *sql.Stmt
+	restoreUrlByShortUrlStmt       *sql.Stmt
+	revokeApiKeyStmt               *sql.Stmt
+	softDeleteExpiredUrlsStmt      *sql.Stmt
+	softDeleteUrlByShortUrlStmt    *sql.Stmt
+	updateUrlStmt                  *sql.Stmt
+	updateUrlDestinationStmt       *sql.Stmt
+	upsertDailyUniqueVisitorsStmt  *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                        tx,
-		tx:                        tx,
-		deleteUrlByShortUrlStmt:   q.deleteUrlByShortUrlStmt,
-		findUrlByShortUrlStmt:     q.findUrlByShortUrlStmt,
-		insertUrlStmt:             q.insertUrlStmt,
-		updateUrlStmt:             q.updateUrlStmt,
-		updateVisitedCountUrlStmt: q.updateVisitedCountUrlStmt,
+		db:                             tx,
+		tx:                             tx,
+		archiveExpiredUrlsStmt:         q.archiveExpiredUrlsStmt,
+		countClicksStmt:                q.countClicksStmt,
+		countClicksByIntervalStmt:      q.countClicksByIntervalStmt,
+		exportUrlsStmt:                 q.exportUrlsStmt,
+		findApiKeyByKeyHashStmt:        q.findApiKeyByKeyHashStmt,
+		findDailyUniqueVisitorsStmt:    q.findDailyUniqueVisitorsStmt,
+		findTopReferrersStmt:           q.findTopReferrersStmt,
+		findTopUserAgentFamiliesStmt:   q.findTopUserAgentFamiliesStmt,
+		findUrlByShortUrlStmt:          q.findUrlByShortUrlStmt,
+		findUrlByShortUrlForUpdateStmt: q.findUrlByShortUrlForUpdateStmt,
+		findUrlRevisionStmt:            q.findUrlRevisionStmt,
+		findUrlsByShortUrlsStmt:        q.findUrlsByShortUrlsStmt,
+		importUrlStmt:                  q.importUrlStmt,
+		importUrlIfNotExistsStmt:       q.importUrlIfNotExistsStmt,
+		importUrlOverwriteStmt:         q.importUrlOverwriteStmt,
+		incrementVisitedCountUrlsStmt:  q.incrementVisitedCountUrlsStmt,
+		insertApiKeyStmt:               q.insertApiKeyStmt,
+		insertApiKeyIfNotExistsStmt:    q.insertApiKeyIfNotExistsStmt,
+		insertAuditLogStmt:             q.insertAuditLogStmt,
+		insertBaselineUrlRevisionStmt:  q.insertBaselineUrlRevisionStmt,
+		insertClicksStmt:               q.insertClicksStmt,
+		insertUrlStmt:                  q.insertUrlStmt,
+		insertUrlRevisionStmt:          q.insertUrlRevisionStmt,
+		listAuditLogsStmt:              q.listAuditLogsStmt,
+		listUrlRevisionsStmt:           q.listUrlRevisionsStmt,
+		listUrlsByCreatedAtStmt:        q.listUrlsByCreatedAtStmt,
+		listUrlsByVisitedCountStmt:     q.listUrlsByVisitedCountStmt,
+		nextShortUrlSequenceStmt:       q.nextShortUrlSequenceStmt,
+		purgeDeletedUrlsStmt:           q.purgeDeletedUrlsStmt,
+		restoreUrlByShortUrlStmt:       q.restoreUrlByShortUrlStmt,
+		revokeApiKeyStmt:               q.revokeApiKeyStmt,
+		softDeleteExpiredUrlsStmt:      q.softDeleteExpiredUrlsStmt,
+		softDeleteUrlByShortUrlStmt:    q.softDeleteUrlByShortUrlStmt,
+		updateUrlStmt:                  q.updateUrlStmt,
+		updateUrlDestinationStmt:       q.updateUrlDestinationStmt,
+		upsertDailyUniqueVisitorsStmt:  q.upsertDailyUniqueVisitorsStmt,
 	}
 }
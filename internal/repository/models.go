@@ -5,16 +5,94 @@
 package repository
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID        uuid.UUID  `json:"id"`
+	OwnerID   uuid.UUID  `json:"owner_id"`
+	Name      string     `json:"name"`
+	KeyHash   string     `json:"key_hash"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	Role      string     `json:"role"`
+}
+
+type ArchivedUrl struct {
+	ID             uuid.UUID     `json:"id"`
+	Url            string        `json:"url"`
+	ShortUrl       string        `json:"short_url"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	VisitedCount   int32         `json:"visited_count"`
+	RedirectStatus int16         `json:"redirect_status"`
+	ExpiresAt      *time.Time    `json:"expires_at"`
+	MaxClicks      *int32        `json:"max_clicks"`
+	ArchivedAt     time.Time     `json:"archived_at"`
+	ActiveFrom     *time.Time    `json:"active_from"`
+	ActiveUntil    *time.Time    `json:"active_until"`
+	OwnerID        uuid.NullUUID `json:"owner_id"`
+}
+
+type AuditLog struct {
+	ID        int64           `json:"id"`
+	ActorID   uuid.NullUUID   `json:"actor_id"`
+	ActorRole string          `json:"actor_role"`
+	Action    string          `json:"action"`
+	ShortUrl  string          `json:"short_url"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	ClientIp  string          `json:"client_ip"`
+	Hashcode  string          `json:"hashcode"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type Click struct {
+	ID              int64     `json:"id"`
+	UrlID           uuid.UUID `json:"url_id"`
+	ClickedAt       time.Time `json:"clicked_at"`
+	Referrer        string    `json:"referrer"`
+	UserAgent       string    `json:"user_agent"`
+	ClientIp        string    `json:"client_ip"`
+	AcceptLanguage  string    `json:"accept_language"`
+	UserAgentFamily string    `json:"user_agent_family"`
+}
+
+type DailyUniqueVisitor struct {
+	UrlID     uuid.UUID `json:"url_id"`
+	Day       time.Time `json:"day"`
+	Visitors  int64     `json:"visitors"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Url struct {
-	ID           uuid.UUID `json:"id"`
-	Url          string    `json:"url"`
-	ShortUrl     string    `json:"short_url"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	VisitedCount int32     `json:"visited_count"`
+	ID              uuid.UUID     `json:"id"`
+	Url             string        `json:"url"`
+	ShortUrl        string        `json:"short_url"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	VisitedCount    int32         `json:"visited_count"`
+	RedirectStatus  int16         `json:"redirect_status"`
+	ExpiresAt       *time.Time    `json:"expires_at"`
+	MaxClicks       *int32        `json:"max_clicks"`
+	ActiveFrom      *time.Time    `json:"active_from"`
+	ActiveUntil     *time.Time    `json:"active_until"`
+	OwnerID         uuid.NullUUID `json:"owner_id"`
+	DestinationHost *string       `json:"destination_host"`
+	DeletedAt       *time.Time    `json:"deleted_at"`
+}
+
+type UrlRevision struct {
+	ID             int64         `json:"id"`
+	UrlID          uuid.UUID     `json:"url_id"`
+	Revision       int32         `json:"revision"`
+	Url            string        `json:"url"`
+	Action         string        `json:"action"`
+	SourceRevision *int32        `json:"source_revision"`
+	ActorID        uuid.NullUUID `json:"actor_id"`
+	ActorRole      string        `json:"actor_role"`
+	CreatedAt      time.Time     `json:"created_at"`
 }
@@ -7,16 +7,165 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const deleteUrlByShortUrl = `-- name: DeleteUrlByShortUrl :one
-delete from urls where short_url = $1 returning id, url, short_url, created_at, updated_at, visited_count
+const archiveExpiredUrls = `-- name: ArchiveExpiredUrls :many
+with archived as (
+    insert into archived_urls (
+        id,
+        url,
+        short_url,
+        created_at,
+        updated_at,
+        visited_count,
+        redirect_status,
+        expires_at,
+        max_clicks,
+        active_from,
+        active_until,
+        owner_id
+    )
+    select
+        candidate.id,
+        candidate.url,
+        candidate.short_url,
+        candidate.created_at,
+        candidate.updated_at,
+        candidate.visited_count,
+        candidate.redirect_status,
+        candidate.expires_at,
+        candidate.max_clicks,
+        candidate.active_from,
+        candidate.active_until,
+        candidate.owner_id
+    from urls as candidate
+    where
+        candidate.deleted_at is null
+        and (
+            (candidate.expires_at is not null and candidate.expires_at <= now())
+            or (
+                candidate.max_clicks is not null
+                and candidate.visited_count >= candidate.max_clicks
+            )
+        )
+    limit $1
+    for update skip locked
+    on conflict (id) do update set
+        url = excluded.url,
+        updated_at = excluded.updated_at,
+        visited_count = excluded.visited_count,
+        redirect_status = excluded.redirect_status,
+        expires_at = excluded.expires_at,
+        max_clicks = excluded.max_clicks,
+        active_from = excluded.active_from,
+        active_until = excluded.active_until,
+        archived_at = now()
+    returning id
+)
+update urls set deleted_at = now()
+where id in (select id from archived)
+returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
+`
+
+func (q *Queries) ArchiveExpiredUrls(ctx context.Context, batchSize int32) ([]Url, error) {
+	rows, err := q.query(ctx, q.archiveExpiredUrlsStmt, archiveExpiredUrls, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.ShortUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.VisitedCount,
+			&i.RedirectStatus,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.ActiveFrom,
+			&i.ActiveUntil,
+			&i.OwnerID,
+			&i.DestinationHost,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUrls = `-- name: ExportUrls :many
+select id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at from urls
+where id > $1 and deleted_at is null
+order by id
+limit $2
+`
+
+type ExportUrlsParams struct {
+	AfterID   uuid.UUID `json:"after_id"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+func (q *Queries) ExportUrls(ctx context.Context, arg ExportUrlsParams) ([]Url, error) {
+	rows, err := q.query(ctx, q.exportUrlsStmt, exportUrls, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.ShortUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.VisitedCount,
+			&i.RedirectStatus,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.ActiveFrom,
+			&i.ActiveUntil,
+			&i.OwnerID,
+			&i.DestinationHost,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findUrlByShortUrl = `-- name: FindUrlByShortUrl :one
+select id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at from urls where short_url = $1
 `
 
-func (q *Queries) DeleteUrlByShortUrl(ctx context.Context, shortUrl string) (Url, error) {
-	row := q.queryRow(ctx, q.deleteUrlByShortUrlStmt, deleteUrlByShortUrl, shortUrl)
+func (q *Queries) FindUrlByShortUrl(ctx context.Context, shortUrl string) (Url, error) {
+	row := q.queryRow(ctx, q.findUrlByShortUrlStmt, findUrlByShortUrl, shortUrl)
 	var i Url
 	err := row.Scan(
 		&i.ID,
@@ -25,16 +174,205 @@ func (q *Queries) DeleteUrlByShortUrl(ctx context.Context, shortUrl string) (Url
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const findUrlByShortUrl = `-- name: FindUrlByShortUrl :one
-select id, url, short_url, created_at, updated_at, visited_count from urls where short_url = $1
+const findUrlByShortUrlForUpdate = `-- name: FindUrlByShortUrlForUpdate :one
+select id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at from urls where short_url = $1 for update
 `
 
-func (q *Queries) FindUrlByShortUrl(ctx context.Context, shortUrl string) (Url, error) {
-	row := q.queryRow(ctx, q.findUrlByShortUrlStmt, findUrlByShortUrl, shortUrl)
+func (q *Queries) FindUrlByShortUrlForUpdate(ctx context.Context, shortUrl string) (Url, error) {
+	row := q.queryRow(ctx, q.findUrlByShortUrlForUpdateStmt, findUrlByShortUrlForUpdate, shortUrl)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.ShortUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const findUrlsByShortUrls = `-- name: FindUrlsByShortUrls :many
+select id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at from urls where short_url = any($1::text[])
+`
+
+func (q *Queries) FindUrlsByShortUrls(ctx context.Context, shortUrls []string) ([]Url, error) {
+	rows, err := q.query(ctx, q.findUrlsByShortUrlsStmt, findUrlsByShortUrls, pq.Array(shortUrls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.ShortUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.VisitedCount,
+			&i.RedirectStatus,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.ActiveFrom,
+			&i.ActiveUntil,
+			&i.OwnerID,
+			&i.DestinationHost,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const importUrl = `-- name: ImportUrl :one
+insert into urls (
+    id,
+    url,
+    short_url,
+    created_at,
+    updated_at,
+    visited_count,
+    redirect_status,
+    expires_at,
+    max_clicks,
+    active_from,
+    active_until,
+    owner_id
+)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
+`
+
+type ImportUrlParams struct {
+	ID             uuid.UUID     `json:"id"`
+	Url            string        `json:"url"`
+	ShortUrl       string        `json:"short_url"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	VisitedCount   int32         `json:"visited_count"`
+	RedirectStatus int16         `json:"redirect_status"`
+	ExpiresAt      *time.Time    `json:"expires_at"`
+	MaxClicks      *int32        `json:"max_clicks"`
+	ActiveFrom     *time.Time    `json:"active_from"`
+	ActiveUntil    *time.Time    `json:"active_until"`
+	OwnerID        uuid.NullUUID `json:"owner_id"`
+}
+
+func (q *Queries) ImportUrl(ctx context.Context, arg ImportUrlParams) (Url, error) {
+	row := q.queryRow(ctx, q.importUrlStmt, importUrl,
+		arg.ID,
+		arg.Url,
+		arg.ShortUrl,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.VisitedCount,
+		arg.RedirectStatus,
+		arg.ExpiresAt,
+		arg.MaxClicks,
+		arg.ActiveFrom,
+		arg.ActiveUntil,
+		arg.OwnerID,
+	)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.ShortUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const importUrlIfNotExists = `-- name: ImportUrlIfNotExists :one
+insert into urls (
+    id,
+    url,
+    short_url,
+    created_at,
+    updated_at,
+    visited_count,
+    redirect_status,
+    expires_at,
+    max_clicks,
+    active_from,
+    active_until,
+    owner_id
+)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+on conflict do nothing
+returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
+`
+
+type ImportUrlIfNotExistsParams struct {
+	ID             uuid.UUID     `json:"id"`
+	Url            string        `json:"url"`
+	ShortUrl       string        `json:"short_url"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	VisitedCount   int32         `json:"visited_count"`
+	RedirectStatus int16         `json:"redirect_status"`
+	ExpiresAt      *time.Time    `json:"expires_at"`
+	MaxClicks      *int32        `json:"max_clicks"`
+	ActiveFrom     *time.Time    `json:"active_from"`
+	ActiveUntil    *time.Time    `json:"active_until"`
+	OwnerID        uuid.NullUUID `json:"owner_id"`
+}
+
+func (q *Queries) ImportUrlIfNotExists(ctx context.Context, arg ImportUrlIfNotExistsParams) (Url, error) {
+	row := q.queryRow(ctx, q.importUrlIfNotExistsStmt, importUrlIfNotExists,
+		arg.ID,
+		arg.Url,
+		arg.ShortUrl,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.VisitedCount,
+		arg.RedirectStatus,
+		arg.ExpiresAt,
+		arg.MaxClicks,
+		arg.ActiveFrom,
+		arg.ActiveUntil,
+		arg.OwnerID,
+	)
 	var i Url
 	err := row.Scan(
 		&i.ID,
@@ -43,22 +381,170 @@ func (q *Queries) FindUrlByShortUrl(ctx context.Context, shortUrl string) (Url,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const importUrlOverwrite = `-- name: ImportUrlOverwrite :one
+insert into urls (
+    id,
+    url,
+    short_url,
+    created_at,
+    updated_at,
+    visited_count,
+    redirect_status,
+    expires_at,
+    max_clicks,
+    active_from,
+    active_until,
+    owner_id
+)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+on conflict (short_url) do update
+set
+    url = excluded.url,
+    created_at = excluded.created_at,
+    updated_at = excluded.updated_at,
+    visited_count = excluded.visited_count,
+    redirect_status = excluded.redirect_status,
+    expires_at = excluded.expires_at,
+    max_clicks = excluded.max_clicks,
+    active_from = excluded.active_from,
+    active_until = excluded.active_until,
+    owner_id = excluded.owner_id
+returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at, (xmax = 0)::boolean as inserted
+`
+
+type ImportUrlOverwriteParams struct {
+	ID             uuid.UUID     `json:"id"`
+	Url            string        `json:"url"`
+	ShortUrl       string        `json:"short_url"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	VisitedCount   int32         `json:"visited_count"`
+	RedirectStatus int16         `json:"redirect_status"`
+	ExpiresAt      *time.Time    `json:"expires_at"`
+	MaxClicks      *int32        `json:"max_clicks"`
+	ActiveFrom     *time.Time    `json:"active_from"`
+	ActiveUntil    *time.Time    `json:"active_until"`
+	OwnerID        uuid.NullUUID `json:"owner_id"`
+}
+
+type ImportUrlOverwriteRow struct {
+	ID              uuid.UUID     `json:"id"`
+	Url             string        `json:"url"`
+	ShortUrl        string        `json:"short_url"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	VisitedCount    int32         `json:"visited_count"`
+	RedirectStatus  int16         `json:"redirect_status"`
+	ExpiresAt       *time.Time    `json:"expires_at"`
+	MaxClicks       *int32        `json:"max_clicks"`
+	ActiveFrom      *time.Time    `json:"active_from"`
+	ActiveUntil     *time.Time    `json:"active_until"`
+	OwnerID         uuid.NullUUID `json:"owner_id"`
+	DestinationHost *string       `json:"destination_host"`
+	DeletedAt       *time.Time    `json:"deleted_at"`
+	Inserted        bool          `json:"inserted"`
+}
+
+func (q *Queries) ImportUrlOverwrite(ctx context.Context, arg ImportUrlOverwriteParams) (ImportUrlOverwriteRow, error) {
+	row := q.queryRow(ctx, q.importUrlOverwriteStmt, importUrlOverwrite,
+		arg.ID,
+		arg.Url,
+		arg.ShortUrl,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.VisitedCount,
+		arg.RedirectStatus,
+		arg.ExpiresAt,
+		arg.MaxClicks,
+		arg.ActiveFrom,
+		arg.ActiveUntil,
+		arg.OwnerID,
+	)
+	var i ImportUrlOverwriteRow
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.ShortUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
+		&i.Inserted,
+	)
+	return i, err
+}
+
+const incrementVisitedCountUrls = `-- name: IncrementVisitedCountUrls :exec
+update urls
+set visited_count = urls.visited_count + visits.delta
+from (
+    select
+        unnest($1::uuid[]) as id,
+        unnest($2::bigint[]) as delta
+) as visits
+where urls.id = visits.id
+`
+
+type IncrementVisitedCountUrlsParams struct {
+	Ids    []uuid.UUID `json:"ids"`
+	Deltas []int64     `json:"deltas"`
+}
+
+func (q *Queries) IncrementVisitedCountUrls(ctx context.Context, arg IncrementVisitedCountUrlsParams) error {
+	_, err := q.exec(ctx, q.incrementVisitedCountUrlsStmt, incrementVisitedCountUrls, pq.Array(arg.Ids), pq.Array(arg.Deltas))
+	return err
+}
+
 const insertUrl = `-- name: InsertUrl :one
-insert into urls(id, url, short_url) values($1, $2, $3) returning id, url, short_url, created_at, updated_at, visited_count
+insert into urls(
+    id, url, short_url, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id
+)
+values($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
 `
 
 type InsertUrlParams struct {
-	ID       uuid.UUID `json:"id"`
-	Url      string    `json:"url"`
-	ShortUrl string    `json:"short_url"`
+	ID             uuid.UUID     `json:"id"`
+	Url            string        `json:"url"`
+	ShortUrl       string        `json:"short_url"`
+	RedirectStatus int16         `json:"redirect_status"`
+	ExpiresAt      *time.Time    `json:"expires_at"`
+	MaxClicks      *int32        `json:"max_clicks"`
+	ActiveFrom     *time.Time    `json:"active_from"`
+	ActiveUntil    *time.Time    `json:"active_until"`
+	OwnerID        uuid.NullUUID `json:"owner_id"`
 }
 
 func (q *Queries) InsertUrl(ctx context.Context, arg InsertUrlParams) (Url, error) {
-	row := q.queryRow(ctx, q.insertUrlStmt, insertUrl, arg.ID, arg.Url, arg.ShortUrl)
+	row := q.queryRow(ctx, q.insertUrlStmt, insertUrl,
+		arg.ID,
+		arg.Url,
+		arg.ShortUrl,
+		arg.RedirectStatus,
+		arg.ExpiresAt,
+		arg.MaxClicks,
+		arg.ActiveFrom,
+		arg.ActiveUntil,
+		arg.OwnerID,
+	)
 	var i Url
 	err := row.Scan(
 		&i.ID,
@@ -67,21 +553,440 @@ func (q *Queries) InsertUrl(ctx context.Context, arg InsertUrlParams) (Url, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const listUrlsByCreatedAt = `-- name: ListUrlsByCreatedAt :many
+select id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at from urls
+where
+    deleted_at is null
+    and ($1::uuid is null or owner_id = $1::uuid)
+    and (
+        $2::timestamptz is null
+        or created_at >= $2::timestamptz
+    )
+    and (
+        $3::timestamptz is null
+        or created_at < $3::timestamptz
+    )
+    and (
+        $4::timestamptz is null
+        or updated_at >= $4::timestamptz
+    )
+    and (
+        $5::timestamptz is null
+        or updated_at < $5::timestamptz
+    )
+    and ($6::text is null or destination_host = $6::text)
+    and (
+        $7::text is null
+        or url ilike $7::text
+        or short_url ilike $7::text
+    )
+    and (
+        $8::timestamp is null
+        or (created_at, id) < ($8::timestamp, $9::uuid)
+    )
+order by created_at desc, id desc
+limit $10
+`
+
+type ListUrlsByCreatedAtParams struct {
+	OwnerID         uuid.NullUUID  `json:"owner_id"`
+	CreatedFrom     sql.NullTime   `json:"created_from"`
+	CreatedTo       sql.NullTime   `json:"created_to"`
+	UpdatedFrom     sql.NullTime   `json:"updated_from"`
+	UpdatedTo       sql.NullTime   `json:"updated_to"`
+	Host            sql.NullString `json:"host"`
+	Search          sql.NullString `json:"search"`
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	CursorID        uuid.UUID      `json:"cursor_id"`
+	RowLimit        int32          `json:"row_limit"`
+}
+
+func (q *Queries) ListUrlsByCreatedAt(ctx context.Context, arg ListUrlsByCreatedAtParams) ([]Url, error) {
+	rows, err := q.query(ctx, q.listUrlsByCreatedAtStmt, listUrlsByCreatedAt,
+		arg.OwnerID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.UpdatedFrom,
+		arg.UpdatedTo,
+		arg.Host,
+		arg.Search,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.ShortUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.VisitedCount,
+			&i.RedirectStatus,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.ActiveFrom,
+			&i.ActiveUntil,
+			&i.OwnerID,
+			&i.DestinationHost,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUrlsByVisitedCount = `-- name: ListUrlsByVisitedCount :many
+select id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at from urls
+where
+    deleted_at is null
+    and ($1::uuid is null or owner_id = $1::uuid)
+    and (
+        $2::timestamptz is null
+        or created_at >= $2::timestamptz
+    )
+    and (
+        $3::timestamptz is null
+        or created_at < $3::timestamptz
+    )
+    and (
+        $4::timestamptz is null
+        or updated_at >= $4::timestamptz
+    )
+    and (
+        $5::timestamptz is null
+        or updated_at < $5::timestamptz
+    )
+    and ($6::text is null or destination_host = $6::text)
+    and (
+        $7::text is null
+        or url ilike $7::text
+        or short_url ilike $7::text
+    )
+    and (
+        $8::int is null
+        or (visited_count, id) < ($8::int, $9::uuid)
+    )
+order by visited_count desc, id desc
+limit $10
+`
+
+type ListUrlsByVisitedCountParams struct {
+	OwnerID            uuid.NullUUID  `json:"owner_id"`
+	CreatedFrom        sql.NullTime   `json:"created_from"`
+	CreatedTo          sql.NullTime   `json:"created_to"`
+	UpdatedFrom        sql.NullTime   `json:"updated_from"`
+	UpdatedTo          sql.NullTime   `json:"updated_to"`
+	Host               sql.NullString `json:"host"`
+	Search             sql.NullString `json:"search"`
+	CursorVisitedCount sql.NullInt32  `json:"cursor_visited_count"`
+	CursorID           uuid.UUID      `json:"cursor_id"`
+	RowLimit           int32          `json:"row_limit"`
+}
+
+func (q *Queries) ListUrlsByVisitedCount(ctx context.Context, arg ListUrlsByVisitedCountParams) ([]Url, error) {
+	rows, err := q.query(ctx, q.listUrlsByVisitedCountStmt, listUrlsByVisitedCount,
+		arg.OwnerID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.UpdatedFrom,
+		arg.UpdatedTo,
+		arg.Host,
+		arg.Search,
+		arg.CursorVisitedCount,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.ShortUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.VisitedCount,
+			&i.RedirectStatus,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.ActiveFrom,
+			&i.ActiveUntil,
+			&i.OwnerID,
+			&i.DestinationHost,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextShortUrlSequence = `-- name: NextShortUrlSequence :one
+select nextval('short_url_seq')::bigint
+`
+
+func (q *Queries) NextShortUrlSequence(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.nextShortUrlSequenceStmt, nextShortUrlSequence)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const purgeDeletedUrls = `-- name: PurgeDeletedUrls :many
+delete from urls
+where id in (
+    select deleted.id from urls as deleted
+    where deleted.deleted_at <= $1::timestamptz
+    limit $2
+    for update skip locked
+)
+returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
+`
+
+type PurgeDeletedUrlsParams struct {
+	DeletedBefore time.Time `json:"deleted_before"`
+	BatchSize     int32     `json:"batch_size"`
+}
+
+func (q *Queries) PurgeDeletedUrls(ctx context.Context, arg PurgeDeletedUrlsParams) ([]Url, error) {
+	rows, err := q.query(ctx, q.purgeDeletedUrlsStmt, purgeDeletedUrls, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.ShortUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.VisitedCount,
+			&i.RedirectStatus,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.ActiveFrom,
+			&i.ActiveUntil,
+			&i.OwnerID,
+			&i.DestinationHost,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreUrlByShortUrl = `-- name: RestoreUrlByShortUrl :one
+update urls set deleted_at = null, updated_at = now()
+where short_url = $1 and deleted_at is not null
+returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
+`
+
+func (q *Queries) RestoreUrlByShortUrl(ctx context.Context, shortUrl string) (Url, error) {
+	row := q.queryRow(ctx, q.restoreUrlByShortUrlStmt, restoreUrlByShortUrl, shortUrl)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.ShortUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const softDeleteExpiredUrls = `-- name: SoftDeleteExpiredUrls :many
+update urls set deleted_at = now()
+where id in (
+    select expired.id from urls as expired
+    where
+        expired.deleted_at is null
+        and (
+            (expired.expires_at is not null and expired.expires_at <= now())
+            or (expired.max_clicks is not null and expired.visited_count >= expired.max_clicks)
+        )
+    limit $1
+    for update skip locked
+)
+returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
+`
+
+func (q *Queries) SoftDeleteExpiredUrls(ctx context.Context, batchSize int32) ([]Url, error) {
+	rows, err := q.query(ctx, q.softDeleteExpiredUrlsStmt, softDeleteExpiredUrls, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.ShortUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.VisitedCount,
+			&i.RedirectStatus,
+			&i.ExpiresAt,
+			&i.MaxClicks,
+			&i.ActiveFrom,
+			&i.ActiveUntil,
+			&i.OwnerID,
+			&i.DestinationHost,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteUrlByShortUrl = `-- name: SoftDeleteUrlByShortUrl :one
+update urls set deleted_at = now()
+where short_url = $1 and deleted_at is null
+returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
+`
+
+func (q *Queries) SoftDeleteUrlByShortUrl(ctx context.Context, shortUrl string) (Url, error) {
+	row := q.queryRow(ctx, q.softDeleteUrlByShortUrlStmt, softDeleteUrlByShortUrl, shortUrl)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.ShortUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const updateUrl = `-- name: UpdateUrl :one
-update urls set url = $2 where short_url = $1 returning id, url, short_url, created_at, updated_at, visited_count
+update urls
+set
+    url = $2,
+    redirect_status = coalesce($3, redirect_status),
+    expires_at = case
+        when $4::boolean then null
+        else coalesce($5, expires_at)
+    end,
+    max_clicks = case
+        when $6::boolean then null
+        else coalesce($7, max_clicks)
+    end,
+    active_from = case
+        when $8::boolean then null
+        else coalesce($9, active_from)
+    end,
+    active_until = case
+        when $10::boolean then null
+        else coalesce($11, active_until)
+    end,
+    updated_at = now()
+where short_url = $1 and deleted_at is null returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
 `
 
 type UpdateUrlParams struct {
-	ShortUrl string `json:"short_url"`
-	Url      string `json:"url"`
+	ShortUrl         string        `json:"short_url"`
+	Url              string        `json:"url"`
+	RedirectStatus   sql.NullInt16 `json:"redirect_status"`
+	ClearExpiresAt   bool          `json:"clear_expires_at"`
+	ExpiresAt        *time.Time    `json:"expires_at"`
+	ClearMaxClicks   bool          `json:"clear_max_clicks"`
+	MaxClicks        *int32        `json:"max_clicks"`
+	ClearActiveFrom  bool          `json:"clear_active_from"`
+	ActiveFrom       *time.Time    `json:"active_from"`
+	ClearActiveUntil bool          `json:"clear_active_until"`
+	ActiveUntil      *time.Time    `json:"active_until"`
 }
 
 func (q *Queries) UpdateUrl(ctx context.Context, arg UpdateUrlParams) (Url, error) {
-	row := q.queryRow(ctx, q.updateUrlStmt, updateUrl, arg.ShortUrl, arg.Url)
+	row := q.queryRow(ctx, q.updateUrlStmt, updateUrl,
+		arg.ShortUrl,
+		arg.Url,
+		arg.RedirectStatus,
+		arg.ClearExpiresAt,
+		arg.ExpiresAt,
+		arg.ClearMaxClicks,
+		arg.MaxClicks,
+		arg.ClearActiveFrom,
+		arg.ActiveFrom,
+		arg.ClearActiveUntil,
+		arg.ActiveUntil,
+	)
 	var i Url
 	err := row.Scan(
 		&i.ID,
@@ -90,21 +995,29 @@ func (q *Queries) UpdateUrl(ctx context.Context, arg UpdateUrlParams) (Url, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const updateVisitedCountUrl = `-- name: UpdateVisitedCountUrl :one
-update urls set visited_count = $2 where id = $1 returning id, url, short_url, created_at, updated_at, visited_count
+const updateUrlDestination = `-- name: UpdateUrlDestination :one
+update urls set url = $2, updated_at = now() where id = $1 returning id, url, short_url, created_at, updated_at, visited_count, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id, destination_host, deleted_at
 `
 
-type UpdateVisitedCountUrlParams struct {
-	ID           uuid.UUID `json:"id"`
-	VisitedCount int32     `json:"visited_count"`
+type UpdateUrlDestinationParams struct {
+	ID  uuid.UUID `json:"id"`
+	Url string    `json:"url"`
 }
 
-func (q *Queries) UpdateVisitedCountUrl(ctx context.Context, arg UpdateVisitedCountUrlParams) (Url, error) {
-	row := q.queryRow(ctx, q.updateVisitedCountUrlStmt, updateVisitedCountUrl, arg.ID, arg.VisitedCount)
+func (q *Queries) UpdateUrlDestination(ctx context.Context, arg UpdateUrlDestinationParams) (Url, error) {
+	row := q.queryRow(ctx, q.updateUrlDestinationStmt, updateUrlDestination, arg.ID, arg.Url)
 	var i Url
 	err := row.Scan(
 		&i.ID,
@@ -113,6 +1026,14 @@ func (q *Queries) UpdateVisitedCountUrl(ctx context.Context, arg UpdateVisitedCo
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.VisitedCount,
+		&i.RedirectStatus,
+		&i.ExpiresAt,
+		&i.MaxClicks,
+		&i.ActiveFrom,
+		&i.ActiveUntil,
+		&i.OwnerID,
+		&i.DestinationHost,
+		&i.DeletedAt,
 	)
 	return i, err
 }
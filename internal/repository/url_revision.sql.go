@@ -0,0 +1,154 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: url_revision.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const findUrlRevision = `-- name: FindUrlRevision :one
+select id, url_id, revision, url, action, source_revision, actor_id, actor_role, created_at from url_revisions where url_id = $1 and revision = $2
+`
+
+type FindUrlRevisionParams struct {
+	UrlID    uuid.UUID `json:"url_id"`
+	Revision int32     `json:"revision"`
+}
+
+func (q *Queries) FindUrlRevision(ctx context.Context, arg FindUrlRevisionParams) (UrlRevision, error) {
+	row := q.queryRow(ctx, q.findUrlRevisionStmt, findUrlRevision, arg.UrlID, arg.Revision)
+	var i UrlRevision
+	err := row.Scan(
+		&i.ID,
+		&i.UrlID,
+		&i.Revision,
+		&i.Url,
+		&i.Action,
+		&i.SourceRevision,
+		&i.ActorID,
+		&i.ActorRole,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertBaselineUrlRevision = `-- name: InsertBaselineUrlRevision :exec
+insert into url_revisions (url_id, revision, url, action, actor_id, created_at)
+select
+    $1::uuid,
+    1,
+    $2::text,
+    'create',
+    $3::uuid,
+    $4::timestamptz
+where not exists (select 1 from url_revisions where url_id = $1::uuid)
+`
+
+type InsertBaselineUrlRevisionParams struct {
+	UrlID     uuid.UUID     `json:"url_id"`
+	Url       string        `json:"url"`
+	OwnerID   uuid.NullUUID `json:"owner_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+func (q *Queries) InsertBaselineUrlRevision(ctx context.Context, arg InsertBaselineUrlRevisionParams) error {
+	_, err := q.exec(ctx, q.insertBaselineUrlRevisionStmt, insertBaselineUrlRevision,
+		arg.UrlID,
+		arg.Url,
+		arg.OwnerID,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const insertUrlRevision = `-- name: InsertUrlRevision :one
+insert into url_revisions (url_id, revision, url, action, source_revision, actor_id, actor_role)
+select
+    $1::uuid,
+    coalesce(max(revision), 0) + 1,
+    $2::text,
+    $3::text,
+    $4::integer,
+    $5::uuid,
+    $6::text
+from url_revisions
+where url_id = $1::uuid
+returning id, url_id, revision, url, action, source_revision, actor_id, actor_role, created_at
+`
+
+type InsertUrlRevisionParams struct {
+	UrlID          uuid.UUID     `json:"url_id"`
+	Url            string        `json:"url"`
+	Action         string        `json:"action"`
+	SourceRevision sql.NullInt32 `json:"source_revision"`
+	ActorID        uuid.NullUUID `json:"actor_id"`
+	ActorRole      string        `json:"actor_role"`
+}
+
+func (q *Queries) InsertUrlRevision(ctx context.Context, arg InsertUrlRevisionParams) (UrlRevision, error) {
+	row := q.queryRow(ctx, q.insertUrlRevisionStmt, insertUrlRevision,
+		arg.UrlID,
+		arg.Url,
+		arg.Action,
+		arg.SourceRevision,
+		arg.ActorID,
+		arg.ActorRole,
+	)
+	var i UrlRevision
+	err := row.Scan(
+		&i.ID,
+		&i.UrlID,
+		&i.Revision,
+		&i.Url,
+		&i.Action,
+		&i.SourceRevision,
+		&i.ActorID,
+		&i.ActorRole,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listUrlRevisions = `-- name: ListUrlRevisions :many
+select id, url_id, revision, url, action, source_revision, actor_id, actor_role, created_at from url_revisions where url_id = $1 order by revision desc
+`
+
+func (q *Queries) ListUrlRevisions(ctx context.Context, urlID uuid.UUID) ([]UrlRevision, error) {
+	rows, err := q.query(ctx, q.listUrlRevisionsStmt, listUrlRevisions, urlID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UrlRevision
+	for rows.Next() {
+		var i UrlRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.UrlID,
+			&i.Revision,
+			&i.Url,
+			&i.Action,
+			&i.SourceRevision,
+			&i.ActorID,
+			&i.ActorRole,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,90 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: visitor.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const findDailyUniqueVisitors = `-- name: FindDailyUniqueVisitors :many
+select
+    day,
+    visitors
+from daily_unique_visitors
+where
+    url_id = $1
+    and day >= ($2::text)::date
+    and day <= ($3::text)::date
+order by day
+`
+
+type FindDailyUniqueVisitorsParams struct {
+	UrlID      uuid.UUID `json:"url_id"`
+	StartedDay string    `json:"started_day"`
+	EndedDay   string    `json:"ended_day"`
+}
+
+type FindDailyUniqueVisitorsRow struct {
+	Day      time.Time `json:"day"`
+	Visitors int64     `json:"visitors"`
+}
+
+func (q *Queries) FindDailyUniqueVisitors(ctx context.Context, arg FindDailyUniqueVisitorsParams) ([]FindDailyUniqueVisitorsRow, error) {
+	rows, err := q.query(ctx, q.findDailyUniqueVisitorsStmt, findDailyUniqueVisitors, arg.UrlID, arg.StartedDay, arg.EndedDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindDailyUniqueVisitorsRow
+	for rows.Next() {
+		var i FindDailyUniqueVisitorsRow
+		if err := rows.Scan(&i.Day, &i.Visitors); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertDailyUniqueVisitors = `-- name: UpsertDailyUniqueVisitors :exec
+insert into daily_unique_visitors (url_id, day, visitors)
+select
+    rolled.url_id,
+    rolled.day,
+    rolled.visitors
+from (
+    select
+        unnest($1::uuid[]) as url_id,
+        unnest($2::text[])::date as day,
+        unnest($3::bigint[]) as visitors
+) as rolled
+where exists (select 1 from urls where urls.id = rolled.url_id)
+on conflict (url_id, day) do update
+    set
+        visitors = greatest(daily_unique_visitors.visitors, excluded.visitors),
+        updated_at = now()
+`
+
+type UpsertDailyUniqueVisitorsParams struct {
+	UrlIds   []uuid.UUID `json:"url_ids"`
+	Days     []string    `json:"days"`
+	Visitors []int64     `json:"visitors"`
+}
+
+func (q *Queries) UpsertDailyUniqueVisitors(ctx context.Context, arg UpsertDailyUniqueVisitorsParams) error {
+	_, err := q.exec(ctx, q.upsertDailyUniqueVisitorsStmt, upsertDailyUniqueVisitors, pq.Array(arg.UrlIds), pq.Array(arg.Days), pq.Array(arg.Visitors))
+	return err
+}
@@ -0,0 +1,7 @@
+package request
+
+type ApiKeyRequest struct {
+	OwnerID string `json:"owner_id,omitempty"`
+	Name    string `json:"name"`
+	Role    string `json:"role,omitempty"`
+}
@@ -0,0 +1,30 @@
+package request
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+type clientIP struct{}
+
+// ClientIP returns the host part of r.RemoteAddr, middleware.RealIP rewrites
+// it from the proxy headers when they are trusted.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func AttachClientIPToContext(c context.Context, ip string) context.Context {
+	return context.WithValue(c, clientIP{}, ip)
+}
+
+// ClientIPFromContext returns the client ip attached by middleware.Logging, it
+// is empty outside of a request.
+func ClientIPFromContext(c context.Context) string {
+	ip, _ := c.Value(clientIP{}).(string)
+	return ip
+}
@@ -2,10 +2,28 @@ package request
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type UrlRequest struct {
-	Url string
+	Url            string     `json:"url"`
+	Alias          string     `json:"alias,omitempty"`
+	RedirectStatus int16      `json:"redirect_status,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	MaxClicks      *int32     `json:"max_clicks,omitempty"`
+	ActiveFrom     *time.Time `json:"active_from,omitempty"`
+	ActiveUntil    *time.Time `json:"active_until,omitempty"`
+}
+
+// UpdateUrlRequest is the body of an update, the clear fields reset the
+// expiration and activation fields to null, fields that are left out are
+// kept.
+type UpdateUrlRequest struct {
+	UrlRequest
+	ClearExpiresAt   bool `json:"clear_expires_at,omitempty"`
+	ClearMaxClicks   bool `json:"clear_max_clicks,omitempty"`
+	ClearActiveFrom  bool `json:"clear_active_from,omitempty"`
+	ClearActiveUntil bool `json:"clear_active_until,omitempty"`
 }
 
 func (u *UrlRequest) String() string {
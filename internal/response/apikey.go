@@ -0,0 +1,33 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+// ApiKeyResponse is an api key without its hash, Key is only set when the key
+// is created.
+type ApiKeyResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	OwnerID   uuid.UUID  `json:"owner_id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	Key       string     `json:"key,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func NewApiKeyResponse(apiKey repository.ApiKey, key string) ApiKeyResponse {
+	return ApiKeyResponse{
+		ID:        apiKey.ID,
+		OwnerID:   apiKey.OwnerID,
+		Name:      apiKey.Name,
+		Role:      apiKey.Role,
+		Key:       key,
+		CreatedAt: apiKey.CreatedAt,
+		RevokedAt: apiKey.RevokedAt,
+	}
+}
@@ -0,0 +1,60 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
+	"github.com/Alturino/url-shortener/internal/log"
+)
+
+// WriteProblem writes err as an RFC 7807 application/problem+json body with
+// the status of its kind, its code and the hashcode of the request, internal
+// and unavailable errors are not detailed to the client.
+func WriteProblem(
+	c context.Context,
+	w http.ResponseWriter,
+	header map[string]string,
+	err error,
+) {
+	logger := zerolog.Ctx(c)
+	hashcode := log.HashcodeFromContext(c)
+	problem := apperrors.Classify(err)
+	statusCode := problem.Kind.StatusCode()
+
+	body := map[string]interface{}{}
+	extender := apperrors.Extender(nil)
+	if errors.As(err, &extender) {
+		for k, v := range extender.Extensions() {
+			body[k] = v
+		}
+	}
+	body["type"] = "about:blank"
+	body["title"] = http.StatusText(statusCode)
+	body["status"] = statusCode
+	body["detail"] = apperrors.Detail(err)
+	body["code"] = problem.Code
+	body["hashcode"] = hashcode
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	if problem.Kind == apperrors.KindUnauthenticated {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	for k, v := range header {
+		w.Header().Add(k, v)
+	}
+	w.WriteHeader(statusCode)
+
+	err = json.NewEncoder(w).Encode(&body)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Str(log.KeyProcess, "WriteProblem").
+			Str(log.KeyHashcode, hashcode).
+			Msgf("failed to write problem response with error=%s", err.Error())
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+func validateActivation(activeFrom *time.Time, activeUntil *time.Time, now time.Time) error {
+	if activeUntil != nil && !activeUntil.After(now) {
+		return fmt.Errorf(
+			"activeUntil=%s must be in the future with error=%w",
+			activeUntil.Format(time.RFC3339),
+			ErrInvalidActivation,
+		)
+	}
+	if activeFrom != nil && activeUntil != nil && !activeFrom.Before(*activeUntil) {
+		return fmt.Errorf(
+			"activeFrom=%s must be before activeUntil=%s with error=%w",
+			activeFrom.Format(time.RFC3339),
+			activeUntil.Format(time.RFC3339),
+			ErrInvalidActivation,
+		)
+	}
+	return nil
+}
+
+// checkActivation reports whether now is outside the activation window of url,
+// it only reads the url so the cached copy is enough to enforce it.
+func checkActivation(url repository.Url, now time.Time) error {
+	if url.ActiveFrom != nil && now.Before(*url.ActiveFrom) {
+		return fmt.Errorf(
+			"shortUrl=%s is active from activeFrom=%s with error=%w",
+			url.ShortUrl,
+			url.ActiveFrom.Format(time.RFC3339),
+			ErrUrlNotYetActive,
+		)
+	}
+	if url.ActiveUntil != nil && !now.Before(*url.ActiveUntil) {
+		return fmt.Errorf(
+			"shortUrl=%s was active until activeUntil=%s with error=%w",
+			url.ShortUrl,
+			url.ActiveUntil.Format(time.RFC3339),
+			ErrUrlActivationEnded,
+		)
+	}
+	return nil
+}
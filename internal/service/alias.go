@@ -0,0 +1,54 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const maxShortUrlLength = 32
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// reservedAliases are path segments used by the application routes, an alias
+// must not shadow them.
+var reservedAliases = map[string]struct{}{
+	"admin":   {},
+	"api":     {},
+	"healthz": {},
+	"metrics": {},
+	"stats":   {},
+	"urls":    {},
+	"warning": {},
+}
+
+func isReservedAlias(alias string) bool {
+	_, ok := reservedAliases[strings.ToLower(alias)]
+	return ok
+}
+
+func validateAlias(alias string, minLength int, maxLength int) error {
+	if maxLength <= 0 || maxLength > maxShortUrlLength {
+		maxLength = maxShortUrlLength
+	}
+	if len(alias) < minLength || len(alias) > maxLength {
+		return fmt.Errorf(
+			"alias=%s length must be between %d and %d with error=%w",
+			alias,
+			minLength,
+			maxLength,
+			ErrInvalidAlias,
+		)
+	}
+	if !aliasPattern.MatchString(alias) {
+		return fmt.Errorf(
+			"alias=%s must start with a letter or digit and only contain letters, digits, '-' or '_' with error=%w",
+			alias,
+			ErrInvalidAlias,
+		)
+	}
+	if isReservedAlias(alias) {
+		return fmt.Errorf("alias=%s is reserved with error=%w", alias, ErrInvalidAlias)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const bootstrapApiKeyName = "bootstrap"
+
+type ApiKeyService struct {
+	queries *repository.Queries
+}
+
+func NewApiKeyService(queries *repository.Queries) *ApiKeyService {
+	return &ApiKeyService{queries: queries}
+}
+
+type CreateApiKeyParams struct {
+	OwnerID uuid.UUID
+	Name    string
+	Role    string
+}
+
+// Authenticate resolves key to the principal of its owner, unknown and
+// revoked keys fail with auth.ErrInvalidCredentials.
+func (s *ApiKeyService) Authenticate(c context.Context, key string) (auth.Principal, error) {
+	c, span := tracer.Start(c, "ApiKeyService Authenticate")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msg("finding api key")
+	apiKey, err := s.queries.FindApiKeyByKeyHash(c, auth.HashApiKey(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("api key is unknown or revoked with error=%w", auth.ErrInvalidCredentials)
+		logger.Error().Err(err).Msg(err.Error())
+		return auth.Principal{}, err
+	}
+	if err != nil {
+		err = fmt.Errorf("failed finding api key with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return auth.Principal{}, err
+	}
+	logger.Info().
+		Str(log.KeyApiKeyID, apiKey.ID.String()).
+		Str(log.KeyOwnerID, apiKey.OwnerID.String()).
+		Msg("found api key")
+
+	return auth.Principal{OwnerID: apiKey.OwnerID, Role: apiKey.Role}, nil
+}
+
+// CreateApiKey stores the hash of a new api key and returns the key, it is
+// the only time the key is available in plain text.
+func (s *ApiKeyService) CreateApiKey(
+	c context.Context,
+	param CreateApiKeyParams,
+) (repository.ApiKey, string, error) {
+	c, span := tracer.Start(c, "ApiKeyService CreateApiKey")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	err := requireAdmin(c)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.ApiKey{}, "", err
+	}
+
+	if param.Role == "" {
+		param.Role = auth.RoleEditor
+	}
+	if !auth.IsValidRole(param.Role) {
+		err = fmt.Errorf(
+			"role=%s must be one of admin, editor or viewer with error=%w",
+			param.Role,
+			ErrInvalidRole,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.ApiKey{}, "", err
+	}
+	if param.OwnerID == uuid.Nil {
+		param.OwnerID = uuid.New()
+	}
+
+	logger.Info().Msg("generating api key")
+	key, err := auth.GenerateApiKey()
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.ApiKey{}, "", err
+	}
+	logger.Info().Msg("generated api key")
+
+	logger.Info().Msgf("inserting api key for ownerId=%s", param.OwnerID.String())
+	inserted, err := s.queries.InsertApiKey(c, repository.InsertApiKeyParams{
+		ID:      uuid.New(),
+		OwnerID: param.OwnerID,
+		Name:    param.Name,
+		KeyHash: auth.HashApiKey(key),
+		Role:    param.Role,
+	})
+	if err != nil {
+		err = fmt.Errorf(
+			"failed inserting api key for ownerId=%s with error=%w",
+			param.OwnerID.String(),
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.ApiKey{}, "", err
+	}
+	logger.Info().
+		Str(log.KeyApiKeyID, inserted.ID.String()).
+		Msgf("inserted api key for ownerId=%s", param.OwnerID.String())
+
+	return inserted, key, nil
+}
+
+func (s *ApiKeyService) RevokeApiKey(c context.Context, id uuid.UUID) (repository.ApiKey, error) {
+	c, span := tracer.Start(c, "ApiKeyService RevokeApiKey")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Str(log.KeyApiKeyID, id.String()).Logger()
+
+	err := requireAdmin(c)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.ApiKey{}, err
+	}
+
+	logger.Info().Msgf("revoking apiKeyId=%s", id.String())
+	revoked, err := s.queries.RevokeApiKey(c, id)
+	if err != nil {
+		err = fmt.Errorf("failed revoking apiKeyId=%s with error=%w", id.String(), err)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.ApiKey{}, err
+	}
+	logger.Info().Msgf("revoked apiKeyId=%s", id.String())
+
+	return revoked, nil
+}
+
+// Bootstrap stores key as an admin api key when it is not stored yet, so the
+// first admin can create the other keys.
+func (s *ApiKeyService) Bootstrap(c context.Context, key string) error {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msg("inserting bootstrap api key")
+	err := s.queries.InsertApiKeyIfNotExists(c, repository.InsertApiKeyIfNotExistsParams{
+		ID:      uuid.New(),
+		OwnerID: uuid.New(),
+		Name:    bootstrapApiKeyName,
+		KeyHash: auth.HashApiKey(key),
+		Role:    auth.RoleAdmin,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed inserting bootstrap api key with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+	logger.Info().Msg("inserted bootstrap api key")
+
+	return nil
+}
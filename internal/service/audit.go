@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+	"github.com/Alturino/url-shortener/internal/request"
+)
+
+const (
+	AuditActionCreate   = "url.create"
+	AuditActionUpdate   = "url.update"
+	AuditActionDelete   = "url.delete"
+	AuditActionRestore  = "url.restore"
+	AuditActionRollback = "url.rollback"
+	AuditActionImport   = "url.import"
+	AuditActionExpire   = "url.expire"
+	AuditActionPurge    = "url.purge"
+
+	// AuditActorSystem is the actor role of the entries of the background
+	// jobs, they run without a principal.
+	AuditActorSystem = "system"
+
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// audit appends an entry for action on shortUrl through queries, callers
+// pass the queries of the transaction of the mutation so the entry is
+// committed or rolled back with it. before and after are snapshots of the
+// target, nil when it did not exist before or after the action. Without a
+// principal in c the entry is recorded for AuditActorSystem.
+func audit(
+	c context.Context,
+	queries *repository.Queries,
+	action string,
+	shortUrl string,
+	before interface{},
+	after interface{},
+) error {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	param := repository.InsertAuditLogParams{
+		Action:   action,
+		ShortUrl: shortUrl,
+		ClientIp: request.ClientIPFromContext(c),
+	}
+	if principal, err := requirePrincipal(c); err == nil {
+		param.ActorID = uuid.NullUUID{UUID: principal.OwnerID, Valid: true}
+		param.ActorRole = principal.Role
+	} else {
+		param.ActorRole = AuditActorSystem
+	}
+	if hashcode, ok := log.LookupHashcode(c); ok {
+		param.Hashcode = hashcode
+	}
+
+	var err error
+	param.Before, err = json.Marshal(before)
+	if err == nil {
+		param.After, err = json.Marshal(after)
+	}
+	if err != nil {
+		err = fmt.Errorf(
+			"failed marshalling audit snapshot of action=%s shortUrl=%s with error=%w",
+			action,
+			shortUrl,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+
+	logger.Info().Msgf("recording audit log action=%s shortUrl=%s", action, shortUrl)
+	err = queries.InsertAuditLog(c, param)
+	if err != nil {
+		err = fmt.Errorf(
+			"failed recording audit log action=%s shortUrl=%s with error=%w",
+			action,
+			shortUrl,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+	logger.Info().Msgf("recorded audit log action=%s shortUrl=%s", action, shortUrl)
+
+	return nil
+}
+
+// ListAuditLogsParams filters the audit log, the time range includes From
+// and excludes To and Cursor is the NextCursor of the previous page.
+type ListAuditLogsParams struct {
+	ActorID  uuid.NullUUID
+	Action   string
+	ShortUrl string
+	Hashcode string
+	From     *time.Time
+	To       *time.Time
+	Cursor   string
+	Limit    int32
+}
+
+// AuditPage is a page of audit logs newest first, NextCursor is empty on the
+// last page.
+type AuditPage struct {
+	Logs       []repository.AuditLog `json:"logs"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+type AuditService struct {
+	queries *repository.Queries
+}
+
+func NewAuditService(queries *repository.Queries) *AuditService {
+	return &AuditService{queries: queries}
+}
+
+func (s *AuditService) ListAuditLogs(
+	c context.Context,
+	param ListAuditLogsParams,
+) (AuditPage, error) {
+	c, span := tracer.Start(c, "AuditService ListAuditLogs")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	err := requireAdmin(c)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return AuditPage{}, err
+	}
+
+	if param.Limit == 0 {
+		param.Limit = defaultAuditLimit
+	}
+	if param.Limit < 0 || param.Limit > maxAuditLimit {
+		err = fmt.Errorf(
+			"limit=%d must be between 1 and %d with error=%w",
+			param.Limit,
+			maxAuditLimit,
+			ErrInvalidAuditParams,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return AuditPage{}, err
+	}
+
+	query := repository.ListAuditLogsParams{
+		ActorID:     param.ActorID,
+		Action:      sql.NullString{String: param.Action, Valid: param.Action != ""},
+		ShortUrl:    sql.NullString{String: param.ShortUrl, Valid: param.ShortUrl != ""},
+		Hashcode:    sql.NullString{String: param.Hashcode, Valid: param.Hashcode != ""},
+		CreatedFrom: nullTime(param.From),
+		CreatedTo:   nullTime(param.To),
+		RowLimit:    param.Limit + 1,
+	}
+	if param.Cursor != "" {
+		cursor, err := strconv.ParseInt(param.Cursor, 10, 64)
+		if err != nil {
+			err = fmt.Errorf(
+				"failed parsing cursor=%s with error=%w: %w",
+				param.Cursor,
+				ErrInvalidAuditParams,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			return AuditPage{}, err
+		}
+		query.CursorID = sql.NullInt64{Int64: cursor, Valid: true}
+	}
+
+	logger.Info().Msg("listing audit logs")
+	logs, err := s.queries.ListAuditLogs(c, query)
+	if err != nil {
+		err = fmt.Errorf("failed listing audit logs with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return AuditPage{}, err
+	}
+	logger.Info().Msgf("listed %d audit logs", len(logs))
+
+	page := AuditPage{Logs: logs}
+	if len(logs) > int(param.Limit) {
+		page.Logs = logs[:param.Limit]
+		page.NextCursor = strconv.FormatInt(page.Logs[len(page.Logs)-1].ID, 10)
+	}
+	if page.Logs == nil {
+		page.Logs = []repository.AuditLog{}
+	}
+	return page, nil
+}
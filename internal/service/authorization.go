@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Alturino/url-shortener/internal/auth"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+func requirePrincipal(c context.Context) (auth.Principal, error) {
+	principal, ok := auth.PrincipalFromContext(c)
+	if !ok {
+		return auth.Principal{}, fmt.Errorf(
+			"request has no api key with error=%w",
+			ErrUnauthenticated,
+		)
+	}
+	return principal, nil
+}
+
+func requireAdmin(c context.Context) error {
+	principal, err := requirePrincipal(c)
+	if err != nil {
+		return err
+	}
+	if !principal.IsAdmin() {
+		return fmt.Errorf(
+			"ownerId=%s is not an admin with error=%w",
+			principal.OwnerID.String(),
+			ErrForbidden,
+		)
+	}
+	return nil
+}
+
+// authorizeOwner allows admins and the owner of url, urls created before
+// ownership was recorded can only be managed by admins.
+func authorizeOwner(c context.Context, url repository.Url) error {
+	principal, err := requirePrincipal(c)
+	if err != nil {
+		return err
+	}
+	if principal.IsAdmin() {
+		return nil
+	}
+	if url.OwnerID.Valid && url.OwnerID.UUID == principal.OwnerID {
+		return nil
+	}
+	return fmt.Errorf(
+		"ownerId=%s does not own shortUrl=%s with error=%w",
+		principal.OwnerID.String(),
+		url.ShortUrl,
+		ErrForbidden,
+	)
+}
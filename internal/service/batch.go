@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+// DefaultBatchMaxItems keeps a batch, which inserts every url in its own
+// savepoint, well within the write timeout of the server.
+const DefaultBatchMaxItems = 1000
+
+// BatchInsertUrlsParams are the urls of a batch, AllOrNothing overrides
+// batch.all_or_nothing when it is set.
+type BatchInsertUrlsParams struct {
+	Urls         []InsertUrlParams
+	AllOrNothing *bool
+}
+
+type BatchItemError struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// BatchItemResult is the outcome of the url at Index of the batch, either
+// ShortUrl or Error is set.
+type BatchItemResult struct {
+	Index    int             `json:"index"`
+	ShortUrl string          `json:"short_url,omitempty"`
+	Error    *BatchItemError `json:"error,omitempty"`
+}
+
+type BatchResult struct {
+	AllOrNothing bool              `json:"all_or_nothing"`
+	Inserted     int               `json:"inserted"`
+	Failed       int               `json:"failed"`
+	Results      []BatchItemResult `json:"results"`
+}
+
+func (r *BatchResult) fail(index int, err error) {
+	r.Results[index] = BatchItemResult{
+		Index: index,
+		Error: &BatchItemError{
+			Code:   apperrors.Classify(err).Code,
+			Detail: apperrors.Detail(err),
+		},
+	}
+}
+
+// abort marks every url that did not fail itself as not inserted because the
+// url at index failed.
+func (r *BatchResult) abort(index int) {
+	r.Inserted, r.Failed = 0, 0
+	for i := range r.Results {
+		if r.Results[i].Error == nil {
+			r.fail(i, fmt.Errorf(
+				"url is not inserted because the url at index=%d failed with error=%w",
+				index,
+				ErrBatchAborted,
+			))
+		}
+		r.Failed++
+	}
+}
+
+// BatchAbortedError is returned when a url of an all or nothing batch fails,
+// it is reported with the status of the failing url and the results of the
+// batch.
+type BatchAbortedError struct {
+	Index  int
+	Err    error
+	Result BatchResult
+}
+
+func (e *BatchAbortedError) Error() string {
+	return fmt.Sprintf("batch aborted by the url at index=%d with error=%s", e.Index, e.Err)
+}
+
+func (e *BatchAbortedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *BatchAbortedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"index": e.Index, "data": e.Result}
+}
+
+// InsertUrls validates and inserts a batch of urls in a single transaction,
+// every insert runs in a savepoint so a failing url only rolls back itself
+// unless the batch is all or nothing. The inserted urls are written to the
+// cache in one pipeline, a cache failure is only logged since the urls are
+// already committed.
+func (s *UrlService) InsertUrls(
+	c context.Context,
+	param BatchInsertUrlsParams,
+) (BatchResult, error) {
+	c, span := tracer.Start(c, "UrlService InsertUrls")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	principal, err := requirePrincipal(c)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return BatchResult{}, err
+	}
+	ownerID := uuid.NullUUID{UUID: principal.OwnerID, Valid: true}
+
+	allOrNothing := s.batchConfig.AllOrNothing
+	if param.AllOrNothing != nil {
+		allOrNothing = *param.AllOrNothing
+	}
+	if len(param.Urls) == 0 || len(param.Urls) > s.batchConfig.MaxItems {
+		err = fmt.Errorf(
+			"batch of %d urls must contain between 1 and %d urls with error=%w",
+			len(param.Urls),
+			s.batchConfig.MaxItems,
+			ErrInvalidBatch,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return BatchResult{}, err
+	}
+
+	result := BatchResult{
+		AllOrNothing: allOrNothing,
+		Results:      make([]BatchItemResult, len(param.Urls)),
+	}
+
+	logger.Info().Msgf("validating batch of %d urls", len(param.Urls))
+	urls := make([]InsertUrlParams, len(param.Urls))
+	for i, url := range param.Urls {
+		urls[i], err = s.validateInsertUrl(c, url)
+		if err == nil {
+			continue
+		}
+		result.fail(i, err)
+		if allOrNothing {
+			result.abort(i)
+			return BatchResult{}, &BatchAbortedError{Index: i, Err: err, Result: result}
+		}
+	}
+	logger.Info().Msgf("validated batch of %d urls", len(param.Urls))
+
+	logger.Info().Msg("beginning batch transaction")
+	tx, err := s.db.BeginTx(c, nil)
+	if err != nil {
+		err = fmt.Errorf("failed beginning batch transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return BatchResult{}, err
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.Error().Err(err).Msgf("failed rolling back batch transaction with error=%s", err)
+		}
+	}()
+	queries := s.queries.WithTx(tx)
+	insert := savepointInsert(tx, queries)
+
+	inserted := make([]repository.Url, 0, len(urls))
+	for i := range urls {
+		if result.Results[i].Error != nil {
+			result.Failed++
+			continue
+		}
+
+		url, err := s.insertUrl(c, ownerID, urls[i], insert)
+		if err != nil {
+			result.fail(i, err)
+			if allOrNothing {
+				result.abort(i)
+				return BatchResult{}, &BatchAbortedError{Index: i, Err: err, Result: result}
+			}
+			result.Failed++
+			continue
+		}
+		err = audit(c, queries, AuditActionCreate, url.ShortUrl, nil, url)
+		if err != nil {
+			return BatchResult{}, err
+		}
+		result.Results[i] = BatchItemResult{Index: i, ShortUrl: url.ShortUrl}
+		result.Inserted++
+		inserted = append(inserted, url)
+	}
+
+	logger.Info().Msgf("committing batch transaction of %d urls", len(inserted))
+	err = tx.Commit()
+	if err != nil {
+		err = fmt.Errorf("failed committing batch transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return BatchResult{}, err
+	}
+	logger.Info().Msgf("committed batch transaction of %d urls", len(inserted))
+
+	logger.Info().Msgf("inserting %d urls to cache", len(inserted))
+	err = s.cache.SetMany(c, inserted)
+	if err != nil {
+		err = fmt.Errorf("failed inserting %d urls to cache with error=%w", len(inserted), err)
+		logger.Error().Err(err).Msg(err.Error())
+		return result, nil
+	}
+	logger.Info().Msgf("inserted %d urls to cache", len(inserted))
+
+	return result, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/config"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const (
+	IPModeHash     = "hash"
+	IPModeTruncate = "truncate"
+	IPModeNone     = "none"
+
+	defaultClickBufferSize = 10000
+	defaultClickBatchSize  = 500
+)
+
+// Visit is the request metadata of a single redirect.
+type Visit struct {
+	Referrer       string
+	UserAgent      string
+	ClientIP       string
+	AcceptLanguage string
+}
+
+type Click struct {
+	UrlID     uuid.UUID
+	ShortUrl  string
+	ClickedAt time.Time
+	Visit
+}
+
+// ClickRecorder writes clicks to postgres in batches from a buffered channel
+// so recording a click never blocks the redirect, clicks are dropped when the
+// buffer is full.
+type ClickRecorder struct {
+	queries  *repository.Queries
+	visitors *UniqueVisitorCounter
+	config   config.Analytics
+	clicks   chan Click
+	interval time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewClickRecorder(
+	queries *repository.Queries,
+	visitors *UniqueVisitorCounter,
+	config config.Analytics,
+) *ClickRecorder {
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultClickBufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultClickBatchSize
+	}
+	interval := config.FlushInterval
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	return &ClickRecorder{
+		queries:  queries,
+		visitors: visitors,
+		config:   config,
+		clicks:   make(chan Click, config.BufferSize),
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Record buffers click for the next batch, clicks recorded after Shutdown are
+// dropped.
+func (r *ClickRecorder) Record(c context.Context, click Click) {
+	select {
+	case <-r.stop:
+		zerolog.Ctx(c).Warn().
+			Str(log.KeyUrlID, click.UrlID.String()).
+			Msg("click recorder is shut down dropping click")
+		return
+	default:
+	}
+
+	click.ClientIP = r.anonymizeIP(click.ClientIP)
+	select {
+	case r.clicks <- click:
+	default:
+		zerolog.Ctx(c).Warn().
+			Str(log.KeyUrlID, click.UrlID.String()).
+			Msg("click buffer is full dropping click")
+	}
+}
+
+// Start writes the buffered clicks until Shutdown is called.
+func (r *ClickRecorder) Start(c context.Context) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "ClickRecorder").Logger()
+	c = logger.WithContext(context.WithoutCancel(c))
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		batch := make([]Click, 0, r.config.BatchSize)
+		for {
+			select {
+			case click := <-r.clicks:
+				batch = r.add(c, batch, click)
+			case <-ticker.C:
+				r.flush(c, batch)
+				batch = batch[:0]
+			case <-r.stop:
+				logger.Info().Msg("flushing buffered clicks before shutdown")
+				for {
+					select {
+					case click := <-r.clicks:
+						batch = r.add(c, batch, click)
+					default:
+						r.flush(c, batch)
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// add appends click to batch and flushes the batch once it is full.
+func (r *ClickRecorder) add(c context.Context, batch []Click, click Click) []Click {
+	batch = append(batch, click)
+	if len(batch) >= r.config.BatchSize {
+		r.flush(c, batch)
+		batch = batch[:0]
+	}
+	return batch
+}
+
+// Shutdown stops accepting clicks and waits until the buffered clicks are
+// written, handlers still running may call Record afterwards and their clicks
+// are dropped. The clicks channel is never closed so a late Record cannot
+// panic.
+func (r *ClickRecorder) Shutdown(c context.Context) error {
+	close(r.stop)
+	select {
+	case <-r.done:
+		return nil
+	case <-c.Done():
+		return fmt.Errorf("failed flushing buffered clicks with error=%w", c.Err())
+	}
+}
+
+func (r *ClickRecorder) flush(c context.Context, batch []Click) {
+	if len(batch) == 0 {
+		return
+	}
+
+	c, span := tracer.Start(c, "ClickRecorder flush")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	param := repository.InsertClicksParams{
+		UrlIds:            make([]uuid.UUID, 0, len(batch)),
+		ClickedAts:        make([]string, 0, len(batch)),
+		Referrers:         make([]string, 0, len(batch)),
+		UserAgents:        make([]string, 0, len(batch)),
+		UserAgentFamilies: make([]string, 0, len(batch)),
+		ClientIps:         make([]string, 0, len(batch)),
+		AcceptLanguages:   make([]string, 0, len(batch)),
+	}
+	for _, click := range batch {
+		param.UrlIds = append(param.UrlIds, click.UrlID)
+		param.ClickedAts = append(param.ClickedAts, click.ClickedAt.Format(time.RFC3339Nano))
+		param.Referrers = append(param.Referrers, click.Referrer)
+		param.UserAgents = append(param.UserAgents, click.UserAgent)
+		param.UserAgentFamilies = append(param.UserAgentFamilies, userAgentFamily(click.UserAgent))
+		param.ClientIps = append(param.ClientIps, click.ClientIP)
+		param.AcceptLanguages = append(param.AcceptLanguages, click.AcceptLanguage)
+	}
+
+	r.visitors.Track(c, batch)
+
+	logger.Info().Msgf("inserting %d clicks", len(batch))
+	err := r.queries.InsertClicks(c, param)
+	if err != nil {
+		err = fmt.Errorf("failed inserting %d clicks with error=%w", len(batch), err)
+		logger.Error().Err(err).Msg(err.Error())
+		return
+	}
+	logger.Info().Msgf("inserted %d clicks", len(batch))
+}
+
+func (r *ClickRecorder) anonymizeIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+
+	switch r.config.IPMode {
+	case IPModeNone:
+		return ""
+	case IPModeTruncate:
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return ""
+		}
+		if v4 := parsed.To4(); v4 != nil {
+			return v4.Mask(net.CIDRMask(24, 32)).String()
+		}
+		return parsed.Mask(net.CIDRMask(48, 128)).String()
+	default:
+		mac := hmac.New(sha256.New, []byte(r.config.IPSalt))
+		mac.Write([]byte(ip))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
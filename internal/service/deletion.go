@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+// checkDeleted reports whether url is a tombstone, its short url keeps
+// answering 410 Gone until the purger removes it.
+func checkDeleted(url repository.Url) error {
+	if url.DeletedAt != nil {
+		return fmt.Errorf(
+			"shortUrl=%s was deleted at deletedAt=%s with error=%w",
+			url.ShortUrl,
+			url.DeletedAt.Format(time.RFC3339),
+			ErrUrlDeleted,
+		)
+	}
+	return nil
+}
+
+// RestoreUrl brings back a deleted url with its clicks as long as the purger
+// did not remove it yet.
+func (s *UrlService) RestoreUrl(c context.Context, shortUrl string) (repository.Url, error) {
+	c, span := tracer.Start(c, "UrlService RestoreUrl")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msg("beginning restore transaction")
+	tx, err := s.db.BeginTx(c, nil)
+	if err != nil {
+		err = fmt.Errorf("failed beginning restore transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	defer rollbackTx(c, tx)
+	queries := s.queries.WithTx(tx)
+
+	logger.Info().Msgf("finding shortUrl=%s", shortUrl)
+	existing, err := queries.FindUrlByShortUrlForUpdate(c, shortUrl)
+	if err != nil {
+		err = fmt.Errorf("failed finding shortUrl=%s with error=%w", shortUrl, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	logger.Info().Msgf("found shortUrl=%s", shortUrl)
+
+	err = authorizeOwner(c, existing)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+
+	logger.Info().Msgf("restoring shortUrl=%s", shortUrl)
+	restored, err := queries.RestoreUrlByShortUrl(c, shortUrl)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("shortUrl=%s is not deleted with error=%w", shortUrl, ErrUrlNotDeleted)
+	}
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msgf("failed restoring shortUrl=%s with error=%s", shortUrl, err.Error())
+		return repository.Url{}, err
+	}
+	logger.Info().Msgf("restored url=%s id=%s", restored.Url, restored.ID.String())
+
+	err = audit(c, queries, AuditActionRestore, shortUrl, existing, restored)
+	if err != nil {
+		return repository.Url{}, err
+	}
+
+	err = s.commitWithCache(c, tx, restored)
+	if err != nil {
+		return repository.Url{}, err
+	}
+	return restored, nil
+}
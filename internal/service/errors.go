@@ -0,0 +1,121 @@
+package service
+
+import apperrors "github.com/Alturino/url-shortener/internal/errors"
+
+var (
+	ErrInvalidRequest = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_request",
+		"invalid request",
+	)
+	ErrInvalidBatch = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_batch",
+		"invalid batch",
+	)
+	ErrBatchAborted = apperrors.New(
+		apperrors.KindConflict,
+		"batch_aborted",
+		"batch aborted",
+	)
+	ErrInvalidImport = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_import",
+		"invalid import",
+	)
+	ErrImportConflict = apperrors.New(
+		apperrors.KindConflict,
+		"import_conflict",
+		"imported url already exists",
+	)
+	ErrInvalidAlias = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_alias",
+		"invalid alias",
+	)
+	ErrInvalidRedirectStatus = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_redirect_status",
+		"invalid redirect status",
+	)
+	ErrInvalidStatsParams = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_stats_params",
+		"invalid stats params",
+	)
+	ErrInvalidListParams = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_list_params",
+		"invalid list params",
+	)
+	ErrInvalidAuditParams = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_audit_params",
+		"invalid audit params",
+	)
+	ErrInvalidExpiration = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_expiration",
+		"invalid expiration",
+	)
+	ErrInvalidActivation = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_activation",
+		"invalid activation window",
+	)
+	ErrInvalidRole = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_role",
+		"invalid role",
+	)
+	ErrShortUrlConflict = apperrors.New(
+		apperrors.KindConflict,
+		"short_url_conflict",
+		"shortUrl already exists",
+	)
+	ErrUrlExpired = apperrors.New(
+		apperrors.KindGone,
+		"url_expired",
+		"url expired",
+	)
+	ErrUrlNotYetActive = apperrors.New(
+		apperrors.KindNotFound,
+		"url_not_yet_active",
+		"url not yet active",
+	)
+	ErrUrlActivationEnded = apperrors.New(
+		apperrors.KindGone,
+		"url_activation_ended",
+		"url activation ended",
+	)
+	ErrUrlDeleted = apperrors.New(
+		apperrors.KindGone,
+		"url_deleted",
+		"url deleted",
+	)
+	ErrUrlNotDeleted = apperrors.New(
+		apperrors.KindConflict,
+		"url_not_deleted",
+		"url is not deleted",
+	)
+	ErrRevisionNotFound = apperrors.New(
+		apperrors.KindNotFound,
+		"revision_not_found",
+		"revision not found",
+	)
+	ErrUrlBlocked = apperrors.New(
+		apperrors.KindForbidden,
+		"url_blocked",
+		"url blocked by policy",
+	)
+	ErrUnauthenticated = apperrors.New(
+		apperrors.KindUnauthenticated,
+		"unauthenticated",
+		"unauthenticated",
+	)
+	ErrForbidden = apperrors.New(
+		apperrors.KindForbidden,
+		"forbidden",
+		"forbidden",
+	)
+)
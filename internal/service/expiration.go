@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+func validateExpiration(expiresAt *time.Time, maxClicks *int32, now time.Time) error {
+	if expiresAt != nil && !expiresAt.After(now) {
+		return fmt.Errorf(
+			"expiresAt=%s must be in the future with error=%w",
+			expiresAt.Format(time.RFC3339),
+			ErrInvalidExpiration,
+		)
+	}
+	if maxClicks != nil && *maxClicks <= 0 {
+		return fmt.Errorf(
+			"maxClicks=%d must be greater than 0 with error=%w",
+			*maxClicks,
+			ErrInvalidExpiration,
+		)
+	}
+	return nil
+}
+
+// validateCleared rejects an update that sets and clears the same field.
+func validateCleared(param UpdateUrlParams) error {
+	fields := []struct {
+		name    string
+		set     bool
+		clear   bool
+		invalid error
+	}{
+		{"expires_at", param.ExpiresAt != nil, param.ClearExpiresAt, ErrInvalidExpiration},
+		{"max_clicks", param.MaxClicks != nil, param.ClearMaxClicks, ErrInvalidExpiration},
+		{"active_from", param.ActiveFrom != nil, param.ClearActiveFrom, ErrInvalidActivation},
+		{"active_until", param.ActiveUntil != nil, param.ClearActiveUntil, ErrInvalidActivation},
+	}
+	for _, field := range fields {
+		if field.set && field.clear {
+			return fmt.Errorf(
+				"%s must not be set and cleared together with error=%w",
+				field.name,
+				field.invalid,
+			)
+		}
+	}
+	return nil
+}
+
+// checkExpiration reports whether url reached its expiration time or click
+// budget, pending are the visits counted by this replica that are not flushed
+// to visited_count yet.
+func checkExpiration(url repository.Url, pending int64, now time.Time) error {
+	if url.ExpiresAt != nil && !now.Before(*url.ExpiresAt) {
+		return fmt.Errorf(
+			"shortUrl=%s expired at expiresAt=%s with error=%w",
+			url.ShortUrl,
+			url.ExpiresAt.Format(time.RFC3339),
+			ErrUrlExpired,
+		)
+	}
+	if url.MaxClicks != nil && int64(url.VisitedCount)+pending >= int64(*url.MaxClicks) {
+		return fmt.Errorf(
+			"shortUrl=%s reached maxClicks=%d with error=%w",
+			url.ShortUrl,
+			*url.MaxClicks,
+			ErrUrlExpired,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"golang.org/x/net/idna"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const (
+	SortCreatedAt    = "created_at"
+	SortVisitedCount = "visited_count"
+
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListUrlsParams filters the listed urls, time ranges include From and
+// exclude To, Search matches a substring of the destination or the short url
+// and Cursor is the NextCursor of the previous page.
+type ListUrlsParams struct {
+	OwnerID     uuid.NullUUID
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	Host        string
+	Search      string
+	Sort        string
+	Cursor      string
+	Limit       int32
+}
+
+// UrlPage is a page of urls, NextCursor is empty on the last page.
+type UrlPage struct {
+	Urls       []repository.Url `json:"urls"`
+	Sort       string           `json:"sort"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// listCursor is the sort key and id of the last url of a page, the next page
+// starts right after it.
+type listCursor struct {
+	sort         string
+	createdAt    time.Time
+	visitedCount int32
+	id           uuid.UUID
+}
+
+func encodeListCursor(sort string, url repository.Url) string {
+	key := url.CreatedAt.UTC().Format(time.RFC3339Nano)
+	if sort == SortVisitedCount {
+		key = strconv.FormatInt(int64(url.VisitedCount), 10)
+	}
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(sort + "|" + key + "|" + url.ID.String()),
+	)
+}
+
+func decodeListCursor(sort string, cursor string) (listCursor, error) {
+	invalid := fmt.Errorf("cursor=%s is malformed with error=%w", cursor, ErrInvalidListParams)
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listCursor{}, invalid
+	}
+	parts := strings.Split(string(decoded), "|")
+	if len(parts) != 3 {
+		return listCursor{}, invalid
+	}
+	if parts[0] != sort {
+		return listCursor{}, fmt.Errorf(
+			"cursor=%s belongs to sort=%s not sort=%s with error=%w",
+			cursor,
+			parts[0],
+			sort,
+			ErrInvalidListParams,
+		)
+	}
+
+	parsed := listCursor{sort: sort}
+	parsed.id, err = uuid.Parse(parts[2])
+	if err != nil {
+		return listCursor{}, invalid
+	}
+	switch sort {
+	case SortVisitedCount:
+		visitedCount, err := strconv.ParseInt(parts[1], 10, 32)
+		if err != nil {
+			return listCursor{}, invalid
+		}
+		parsed.visitedCount = int32(visitedCount)
+	default:
+		parsed.createdAt, err = time.Parse(time.RFC3339Nano, parts[1])
+		if err != nil {
+			return listCursor{}, invalid
+		}
+	}
+	return parsed, nil
+}
+
+func validateListParams(param ListUrlsParams) (ListUrlsParams, error) {
+	if param.Sort == "" {
+		param.Sort = SortCreatedAt
+	}
+	if param.Limit <= 0 {
+		param.Limit = defaultListLimit
+	}
+
+	if param.Sort != SortCreatedAt && param.Sort != SortVisitedCount {
+		return param, fmt.Errorf(
+			"sort=%s must be one of created_at or visited_count with error=%w",
+			param.Sort,
+			ErrInvalidListParams,
+		)
+	}
+	if param.Limit > maxListLimit {
+		return param, fmt.Errorf(
+			"limit=%d must not exceed %d with error=%w",
+			param.Limit,
+			maxListLimit,
+			ErrInvalidListParams,
+		)
+	}
+	if param.Host != "" {
+		host, err := idna.Lookup.ToASCII(strings.ToLower(strings.TrimSpace(param.Host)))
+		if err != nil {
+			return param, fmt.Errorf(
+				"host=%s is not a valid host with error=%w",
+				param.Host,
+				ErrInvalidListParams,
+			)
+		}
+		param.Host = host
+	}
+	return param, nil
+}
+
+// searchPattern matches search as a literal substring with ilike.
+func searchPattern(search string) sql.NullString {
+	if search == "" {
+		return sql.NullString{}
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+	return sql.NullString{String: "%" + escaped + "%", Valid: true}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// ListUrls returns a page of urls sorted by param.Sort in descending order,
+// callers that are not admins only see their own urls.
+func (s *UrlService) ListUrls(c context.Context, param ListUrlsParams) (UrlPage, error) {
+	c, span := tracer.Start(c, "UrlService ListUrls")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	principal, err := requirePrincipal(c)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return UrlPage{}, err
+	}
+	if !principal.IsAdmin() {
+		if param.OwnerID.Valid && param.OwnerID.UUID != principal.OwnerID {
+			err = fmt.Errorf(
+				"ownerId=%s cannot list the urls of ownerId=%s with error=%w",
+				principal.OwnerID.String(),
+				param.OwnerID.UUID.String(),
+				ErrForbidden,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			return UrlPage{}, err
+		}
+		param.OwnerID = uuid.NullUUID{UUID: principal.OwnerID, Valid: true}
+	}
+
+	logger.Info().Msg("validating list params")
+	param, err = validateListParams(param)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return UrlPage{}, err
+	}
+	cursor := listCursor{sort: param.Sort}
+	if param.Cursor != "" {
+		cursor, err = decodeListCursor(param.Sort, param.Cursor)
+		if err != nil {
+			logger.Error().Err(err).Msg(err.Error())
+			return UrlPage{}, err
+		}
+	}
+	logger.Info().Msg("validated list params")
+
+	host := sql.NullString{String: param.Host, Valid: param.Host != ""}
+	rowLimit := param.Limit + 1
+
+	logger.Info().Msgf("listing urls sort=%s limit=%d", param.Sort, param.Limit)
+	var urls []repository.Url
+	switch param.Sort {
+	case SortVisitedCount:
+		urls, err = s.queries.ListUrlsByVisitedCount(c, repository.ListUrlsByVisitedCountParams{
+			OwnerID:     param.OwnerID,
+			CreatedFrom: nullTime(param.CreatedFrom),
+			CreatedTo:   nullTime(param.CreatedTo),
+			UpdatedFrom: nullTime(param.UpdatedFrom),
+			UpdatedTo:   nullTime(param.UpdatedTo),
+			Host:        host,
+			Search:      searchPattern(param.Search),
+			CursorVisitedCount: sql.NullInt32{
+				Int32: cursor.visitedCount,
+				Valid: param.Cursor != "",
+			},
+			CursorID: cursor.id,
+			RowLimit: rowLimit,
+		})
+	default:
+		urls, err = s.queries.ListUrlsByCreatedAt(c, repository.ListUrlsByCreatedAtParams{
+			OwnerID:     param.OwnerID,
+			CreatedFrom: nullTime(param.CreatedFrom),
+			CreatedTo:   nullTime(param.CreatedTo),
+			UpdatedFrom: nullTime(param.UpdatedFrom),
+			UpdatedTo:   nullTime(param.UpdatedTo),
+			Host:        host,
+			Search:      searchPattern(param.Search),
+			CursorCreatedAt: sql.NullTime{
+				Time:  cursor.createdAt,
+				Valid: param.Cursor != "",
+			},
+			CursorID: cursor.id,
+			RowLimit: rowLimit,
+		})
+	}
+	if err != nil {
+		err = fmt.Errorf("failed listing urls sort=%s with error=%w", param.Sort, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return UrlPage{}, err
+	}
+	logger.Info().Msgf("listed %d urls sort=%s", len(urls), param.Sort)
+
+	page := UrlPage{Urls: urls, Sort: param.Sort}
+	if len(urls) > int(param.Limit) {
+		page.Urls = urls[:param.Limit]
+		page.NextCursor = encodeListCursor(param.Sort, page.Urls[len(page.Urls)-1])
+	}
+	if page.Urls == nil {
+		page.Urls = []repository.Url{}
+	}
+	return page, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	neturl "net/url"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+// checkPolicy fails with an error wrapping policy.ErrBlocked when the host of
+// destination is blocked, it is a no-op without a policy engine.
+func (s *UrlService) checkPolicy(c context.Context, destination *neturl.URL) error {
+	if s.policy == nil {
+		return nil
+	}
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msgf("checking policy of url=%s", destination.String())
+	err := s.policy.Check(destination.Hostname())
+	if err != nil {
+		err = fmt.Errorf("url=%s is not allowed with error=%w", destination.String(), err)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+	logger.Info().Msgf("checked policy of url=%s", destination.String())
+
+	return nil
+}
+
+// checkRedirectPolicy fails with ErrUrlBlocked when policy.check_on_redirect
+// is set and the destination of url got blocked after it was created.
+func (s *UrlService) checkRedirectPolicy(url repository.Url) error {
+	if s.policy == nil || !s.policyConfig.CheckOnRedirect {
+		return nil
+	}
+
+	destination, err := neturl.Parse(url.Url)
+	if err != nil {
+		return fmt.Errorf("url=%s is malformed with error=%w", url.Url, ErrUrlBlocked)
+	}
+	err = s.policy.Check(destination.Hostname())
+	if err != nil {
+		return fmt.Errorf("shortUrl=%s %s with error=%w", url.ShortUrl, err.Error(), ErrUrlBlocked)
+	}
+	return nil
+}
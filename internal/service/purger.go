@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/cache"
+	"github.com/Alturino/url-shortener/internal/config"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const (
+	defaultQuarantine     = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+	defaultPurgeBatchSize = 500
+)
+
+// UrlPurger periodically removes the urls deleted longer than the quarantine
+// ago together with their clicks, their short urls can be reissued after.
+type UrlPurger struct {
+	db      *sql.DB
+	queries *repository.Queries
+	cache   cache.UrlCache
+	config  config.Deletion
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewUrlPurger(
+	db *sql.DB,
+	queries *repository.Queries,
+	cache cache.UrlCache,
+	config config.Deletion,
+) *UrlPurger {
+	if config.Quarantine <= 0 {
+		config.Quarantine = defaultQuarantine
+	}
+	if config.PurgeInterval <= 0 {
+		config.PurgeInterval = defaultPurgeInterval
+	}
+	if config.PurgeBatchSize <= 0 {
+		config.PurgeBatchSize = defaultPurgeBatchSize
+	}
+	return &UrlPurger{
+		db:      db,
+		queries: queries,
+		cache:   cache,
+		config:  config,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start purges the deleted urls every purge interval until Shutdown is called.
+func (p *UrlPurger) Start(c context.Context) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "UrlPurger").Logger()
+	c = logger.WithContext(context.WithoutCancel(c))
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.config.PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.Purge(c)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the purge loop, a purge in progress is finished first.
+func (p *UrlPurger) Shutdown(c context.Context) error {
+	close(p.stop)
+	select {
+	case <-p.done:
+		return nil
+	case <-c.Done():
+		return fmt.Errorf("failed stopping url purger with error=%w", c.Err())
+	}
+}
+
+// Purge removes the urls past their quarantine in batches until none is left
+// and drops their tombstones from the cache.
+func (p *UrlPurger) Purge(c context.Context) {
+	c, span := tracer.Start(c, "UrlPurger Purge")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	deletedBefore := time.Now().Add(-p.config.Quarantine)
+	for {
+		logger.Info().
+			Msgf("purging urls deleted before=%s", deletedBefore.Format(time.RFC3339))
+		urls, err := p.purgeBatch(c, deletedBefore)
+		if err != nil {
+			err = fmt.Errorf("failed purging deleted urls with error=%w", err)
+			logger.Error().Err(err).Msg(err.Error())
+			return
+		}
+		logger.Info().Msgf("purged %d deleted urls", len(urls))
+
+		for _, url := range urls {
+			err := p.cache.Delete(c, url.ShortUrl)
+			if err != nil {
+				err = fmt.Errorf(
+					"failed deleting purged shortUrl=%s from cache with error=%w",
+					url.ShortUrl,
+					err,
+				)
+				logger.Error().Err(err).Msg(err.Error())
+			}
+		}
+
+		if len(urls) < int(p.config.PurgeBatchSize) {
+			return
+		}
+	}
+}
+
+// purgeBatch removes a batch of urls deleted before deletedBefore and audits
+// each of them in one transaction.
+func (p *UrlPurger) purgeBatch(
+	c context.Context,
+	deletedBefore time.Time,
+) ([]repository.Url, error) {
+	tx, err := p.db.BeginTx(c, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed beginning purge transaction with error=%w", err)
+	}
+	defer rollbackTx(c, tx)
+	queries := p.queries.WithTx(tx)
+
+	urls, err := queries.PurgeDeletedUrls(c, repository.PurgeDeletedUrlsParams{
+		DeletedBefore: deletedBefore,
+		BatchSize:     p.config.PurgeBatchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, url := range urls {
+		err = audit(c, queries, AuditActionPurge, url.ShortUrl, url, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed committing purge transaction with error=%w", err)
+	}
+	return urls, nil
+}
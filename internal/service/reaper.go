@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/cache"
+	"github.com/Alturino/url-shortener/internal/config"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const (
+	ReaperModeDelete  = "delete"
+	ReaperModeArchive = "archive"
+
+	defaultReaperInterval  = time.Hour
+	defaultReaperBatchSize = 500
+)
+
+// UrlReaper periodically deletes the urls that reached their expiration time
+// or click budget, the archive mode also copies them to archived_urls. Reaped
+// urls stay as tombstones answering as expired until the UrlPurger removes
+// them after the quarantine, so their short urls are not reissued before.
+type UrlReaper struct {
+	db       *sql.DB
+	queries  *repository.Queries
+	cache    cache.UrlCache
+	config   config.Expiration
+	interval time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewUrlReaper(
+	db *sql.DB,
+	queries *repository.Queries,
+	cache cache.UrlCache,
+	config config.Expiration,
+) (*UrlReaper, error) {
+	interval := config.ReaperInterval
+	if interval <= 0 {
+		interval = defaultReaperInterval
+	}
+	if config.ReaperBatchSize <= 0 {
+		config.ReaperBatchSize = defaultReaperBatchSize
+	}
+	switch config.ReaperMode {
+	case "":
+		config.ReaperMode = ReaperModeDelete
+	case ReaperModeDelete, ReaperModeArchive:
+	default:
+		return nil, fmt.Errorf(
+			"reaper_mode=%s must be one of %s or %s",
+			config.ReaperMode,
+			ReaperModeDelete,
+			ReaperModeArchive,
+		)
+	}
+	return &UrlReaper{
+		db:       db,
+		queries:  queries,
+		cache:    cache,
+		config:   config,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start reaps the expired urls every interval until Shutdown is called.
+func (r *UrlReaper) Start(c context.Context) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "UrlReaper").Logger()
+	c = logger.WithContext(context.WithoutCancel(c))
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.Reap(c)
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the reap loop, a reap in progress is finished first.
+func (r *UrlReaper) Shutdown(c context.Context) error {
+	close(r.stop)
+	select {
+	case <-r.done:
+		return nil
+	case <-c.Done():
+		return fmt.Errorf("failed stopping url reaper with error=%w", c.Err())
+	}
+}
+
+// Reap deletes expired urls in batches until none is left.
+func (r *UrlReaper) Reap(c context.Context) {
+	c, span := tracer.Start(c, "UrlReaper Reap")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	for {
+		logger.Info().Msgf("reaping expired urls with mode=%s", r.config.ReaperMode)
+		urls, err := r.reapBatch(c)
+		if err != nil {
+			err = fmt.Errorf(
+				"failed reaping expired urls with mode=%s with error=%w",
+				r.config.ReaperMode,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			return
+		}
+		logger.Info().
+			Msgf("reaped %d expired urls with mode=%s", len(urls), r.config.ReaperMode)
+
+		for _, url := range urls {
+			err := r.cache.Delete(c, url.ShortUrl)
+			if err != nil {
+				err = fmt.Errorf(
+					"failed deleting reaped shortUrl=%s from cache with error=%w",
+					url.ShortUrl,
+					err,
+				)
+				logger.Error().Err(err).Msg(err.Error())
+			}
+		}
+
+		if len(urls) < int(r.config.ReaperBatchSize) {
+			return
+		}
+	}
+}
+
+// reapBatch deletes a batch of expired urls and audits each of them in one
+// transaction.
+func (r *UrlReaper) reapBatch(c context.Context) ([]repository.Url, error) {
+	tx, err := r.db.BeginTx(c, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed beginning reap transaction with error=%w", err)
+	}
+	defer rollbackTx(c, tx)
+	queries := r.queries.WithTx(tx)
+
+	var urls []repository.Url
+	switch r.config.ReaperMode {
+	case ReaperModeArchive:
+		urls, err = queries.ArchiveExpiredUrls(c, r.config.ReaperBatchSize)
+	default:
+		urls, err = queries.SoftDeleteExpiredUrls(c, r.config.ReaperBatchSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, url := range urls {
+		before := url
+		before.DeletedAt = nil
+		err = audit(c, queries, AuditActionExpire, url.ShortUrl, before, url)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed committing reap transaction with error=%w", err)
+	}
+	return urls, nil
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const defaultRedirectStatus = http.StatusFound
+
+func validateRedirectStatus(status int16) error {
+	switch status {
+	case http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return nil
+	default:
+		return fmt.Errorf(
+			"redirectStatus=%d must be one of 301, 302, 307 or 308 with error=%w",
+			status,
+			ErrInvalidRedirectStatus,
+		)
+	}
+}
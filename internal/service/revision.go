@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const (
+	RevisionActionCreate   = "create"
+	RevisionActionUpdate   = "update"
+	RevisionActionRollback = "rollback"
+	RevisionActionImport   = "import"
+)
+
+// UrlHistory is every destination of a url, newest revision first.
+type UrlHistory struct {
+	Url       repository.Url           `json:"url"`
+	Revisions []repository.UrlRevision `json:"revisions"`
+}
+
+// recordRevision appends the destination of updated to the revisions of the
+// url, urls changed for the first time get the destination of existing
+// recorded as revision 1 before.
+func recordRevision(
+	c context.Context,
+	queries *repository.Queries,
+	existing repository.Url,
+	updated repository.Url,
+	action string,
+	source *int32,
+) (repository.UrlRevision, error) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msgf("recording revision of shortUrl=%s", updated.ShortUrl)
+	err := queries.InsertBaselineUrlRevision(c, repository.InsertBaselineUrlRevisionParams{
+		UrlID:     existing.ID,
+		Url:       existing.Url,
+		OwnerID:   existing.OwnerID,
+		CreatedAt: existing.CreatedAt,
+	})
+	if err != nil {
+		err = fmt.Errorf(
+			"failed recording baseline revision of shortUrl=%s with error=%w",
+			updated.ShortUrl,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.UrlRevision{}, err
+	}
+
+	param := repository.InsertUrlRevisionParams{
+		UrlID:  updated.ID,
+		Url:    updated.Url,
+		Action: action,
+	}
+	if source != nil {
+		param.SourceRevision = sql.NullInt32{Int32: *source, Valid: true}
+	}
+	if principal, err := requirePrincipal(c); err == nil {
+		param.ActorID = uuid.NullUUID{UUID: principal.OwnerID, Valid: true}
+		param.ActorRole = principal.Role
+	}
+	revision, err := queries.InsertUrlRevision(c, param)
+	if err != nil {
+		err = fmt.Errorf(
+			"failed recording revision of shortUrl=%s with error=%w",
+			updated.ShortUrl,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.UrlRevision{}, err
+	}
+	logger.Info().Msgf("recorded revision=%d of shortUrl=%s", revision.Revision, updated.ShortUrl)
+
+	return revision, nil
+}
+
+// GetUrlHistory lists the destinations shortUrl had, a url that was never
+// changed has no revisions yet.
+func (s *UrlService) GetUrlHistory(c context.Context, shortUrl string) (UrlHistory, error) {
+	c, span := tracer.Start(c, "UrlService GetUrlHistory")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msgf("finding shortUrl=%s", shortUrl)
+	url, err := s.queries.FindUrlByShortUrl(c, shortUrl)
+	if err != nil {
+		err = fmt.Errorf("failed finding shortUrl=%s with error=%w", shortUrl, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return UrlHistory{}, err
+	}
+	logger.Info().Msgf("found shortUrl=%s", shortUrl)
+
+	err = authorizeOwner(c, url)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return UrlHistory{}, err
+	}
+
+	logger.Info().Msgf("listing revisions of shortUrl=%s", shortUrl)
+	revisions, err := s.queries.ListUrlRevisions(c, url.ID)
+	if err != nil {
+		err = fmt.Errorf("failed listing revisions of shortUrl=%s with error=%w", shortUrl, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return UrlHistory{}, err
+	}
+	if revisions == nil {
+		revisions = []repository.UrlRevision{}
+	}
+	logger.Info().Msgf("listed %d revisions of shortUrl=%s", len(revisions), shortUrl)
+
+	return UrlHistory{Url: url, Revisions: revisions}, nil
+}
+
+// RollbackUrl points shortUrl back to the destination of revision and records
+// the rollback as a new revision. The destination is validated again so a
+// rollback cannot bring back a destination the policy blocks by now.
+func (s *UrlService) RollbackUrl(
+	c context.Context,
+	shortUrl string,
+	revision int32,
+) (repository.Url, error) {
+	c, span := tracer.Start(c, "UrlService RollbackUrl")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msg("beginning rollback transaction")
+	tx, err := s.db.BeginTx(c, nil)
+	if err != nil {
+		err = fmt.Errorf("failed beginning rollback transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	defer rollbackTx(c, tx)
+	queries := s.queries.WithTx(tx)
+
+	logger.Info().Msgf("finding shortUrl=%s", shortUrl)
+	existing, err := queries.FindUrlByShortUrlForUpdate(c, shortUrl)
+	if err != nil {
+		err = fmt.Errorf("failed finding shortUrl=%s with error=%w", shortUrl, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	logger = logger.With().
+		Str(log.KeyOldUrl, existing.Url).
+		Str(log.KeyUrlID, existing.ID.String()).
+		Logger()
+	logger.Info().Msgf("found shortUrl=%s", shortUrl)
+
+	err = authorizeOwner(c, existing)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+
+	err = checkDeleted(existing)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+
+	logger.Info().Msgf("finding revision=%d of shortUrl=%s", revision, shortUrl)
+	target, err := queries.FindUrlRevision(c, repository.FindUrlRevisionParams{
+		UrlID:    existing.ID,
+		Revision: revision,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf(
+			"revision=%d of shortUrl=%s not found with error=%w",
+			revision,
+			shortUrl,
+			ErrRevisionNotFound,
+		)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	logger.Info().Msgf("found revision=%d of shortUrl=%s", revision, shortUrl)
+
+	logger.Info().Msgf("validating destination url=%s", target.Url)
+	destination, err := s.destinations.Validate(target.Url)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	err = s.checkPolicy(c, destination)
+	if err != nil {
+		return repository.Url{}, err
+	}
+	logger.Info().Msgf("validated destination url=%s", target.Url)
+
+	logger.Info().Msgf("rolling back shortUrl=%s to revision=%d", shortUrl, revision)
+	updated, err := queries.UpdateUrlDestination(c, repository.UpdateUrlDestinationParams{
+		ID:  existing.ID,
+		Url: destination.String(),
+	})
+	if err != nil {
+		err = fmt.Errorf(
+			"failed rolling back shortUrl=%s to revision=%d with error=%w",
+			shortUrl,
+			revision,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	logger.Info().Msgf("rolled back shortUrl=%s to url=%s", shortUrl, updated.Url)
+
+	_, err = recordRevision(c, queries, existing, updated, RevisionActionRollback, &revision)
+	if err != nil {
+		return repository.Url{}, err
+	}
+
+	err = audit(c, queries, AuditActionRollback, shortUrl, existing, updated)
+	if err != nil {
+		return repository.Url{}, err
+	}
+
+	err = s.commitWithCache(c, tx, updated)
+	if err != nil {
+		return repository.Url{}, err
+	}
+	return updated, nil
+}
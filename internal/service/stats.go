@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+
+	defaultStatsRange = 7 * 24 * time.Hour
+	defaultStatsLimit = 10
+	maxStatsLimit     = 100
+	maxStatsBuckets   = 1000
+)
+
+var intervalDurations = map[string]time.Duration{
+	IntervalHour: time.Hour,
+	IntervalDay:  24 * time.Hour,
+	IntervalWeek: 7 * 24 * time.Hour,
+}
+
+type StatsParams struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	Limit    int32
+}
+
+type Bucket struct {
+	Time   time.Time `json:"time"`
+	Clicks int64     `json:"clicks"`
+}
+
+type TopEntry struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+// Stats is the click breakdown of a url between From and To, UniqueVisitors
+// is estimated from the distinct anonymized client ips and
+// DailyUniqueVisitors from the HyperLogLog of each day.
+type Stats struct {
+	Url                 repository.Url  `json:"url"`
+	From                time.Time       `json:"from"`
+	To                  time.Time       `json:"to"`
+	Interval            string          `json:"interval"`
+	Clicks              int64           `json:"clicks"`
+	UniqueVisitors      int64           `json:"unique_visitors"`
+	DailyUniqueVisitors []DailyVisitors `json:"daily_unique_visitors"`
+	Buckets             []Bucket        `json:"buckets"`
+	TopReferrers        []TopEntry      `json:"top_referrers"`
+	TopUserAgents       []TopEntry      `json:"top_user_agents"`
+}
+
+func validateStatsParams(param StatsParams) (StatsParams, error) {
+	if param.To.IsZero() {
+		param.To = time.Now()
+	}
+	if param.From.IsZero() {
+		param.From = param.To.Add(-defaultStatsRange)
+	}
+	if param.Interval == "" {
+		param.Interval = IntervalDay
+	}
+	if param.Limit <= 0 {
+		param.Limit = defaultStatsLimit
+	}
+
+	duration, ok := intervalDurations[param.Interval]
+	if !ok {
+		return param, fmt.Errorf(
+			"interval=%s must be one of hour, day or week with error=%w",
+			param.Interval,
+			ErrInvalidStatsParams,
+		)
+	}
+	if !param.From.Before(param.To) {
+		return param, fmt.Errorf(
+			"from=%s must be before to=%s with error=%w",
+			param.From.Format(time.RFC3339),
+			param.To.Format(time.RFC3339),
+			ErrInvalidStatsParams,
+		)
+	}
+	if param.To.Sub(param.From)/duration > maxStatsBuckets {
+		return param, fmt.Errorf(
+			"range from=%s to=%s exceeds %d buckets of interval=%s with error=%w",
+			param.From.Format(time.RFC3339),
+			param.To.Format(time.RFC3339),
+			maxStatsBuckets,
+			param.Interval,
+			ErrInvalidStatsParams,
+		)
+	}
+	if param.Limit > maxStatsLimit {
+		return param, fmt.Errorf(
+			"limit=%d must not exceed %d with error=%w",
+			param.Limit,
+			maxStatsLimit,
+			ErrInvalidStatsParams,
+		)
+	}
+	return param, nil
+}
+
+func (s *UrlService) GetUrlStats(
+	c context.Context,
+	shortUrl string,
+	param StatsParams,
+) (Stats, error) {
+	c, span := tracer.Start(c, "UrlService GetUrlStats")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msg("validating stats params")
+	param, err := validateStatsParams(param)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return Stats{}, err
+	}
+	logger.Info().Msg("validated stats params")
+
+	url, err := s.findUrl(c, shortUrl)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	err = authorizeOwner(c, url)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return Stats{}, err
+	}
+
+	stats := Stats{
+		Url:           url,
+		From:          param.From,
+		To:            param.To,
+		Interval:      param.Interval,
+		Buckets:       []Bucket{},
+		TopReferrers:  []TopEntry{},
+		TopUserAgents: []TopEntry{},
+	}
+
+	logger.Info().Msgf("counting clicks of shortUrl=%s", shortUrl)
+	total, err := s.queries.CountClicks(c, repository.CountClicksParams{
+		UrlID:     url.ID,
+		StartedAt: param.From,
+		EndedAt:   param.To,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed counting clicks of shortUrl=%s with error=%w", shortUrl, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return Stats{}, err
+	}
+	stats.Clicks = total.Clicks
+	stats.UniqueVisitors = total.UniqueVisitors
+	logger.Info().Msgf("counted clicks of shortUrl=%s", shortUrl)
+
+	stats.DailyUniqueVisitors, err = s.visitors.Daily(c, url, param.From, param.To)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	logger.Info().Msgf("counting clicks of shortUrl=%s by interval=%s", shortUrl, param.Interval)
+	buckets, err := s.queries.CountClicksByInterval(c, repository.CountClicksByIntervalParams{
+		Interval:  param.Interval,
+		StartedAt: param.From,
+		EndedAt:   param.To,
+		UrlID:     url.ID,
+	})
+	if err != nil {
+		err = fmt.Errorf(
+			"failed counting clicks of shortUrl=%s by interval=%s with error=%w",
+			shortUrl,
+			param.Interval,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return Stats{}, err
+	}
+	for _, bucket := range buckets {
+		stats.Buckets = append(stats.Buckets, Bucket{Time: bucket.Bucket, Clicks: bucket.Clicks})
+	}
+	logger.Info().Msgf("counted clicks of shortUrl=%s by interval=%s", shortUrl, param.Interval)
+
+	logger.Info().Msgf("finding top referrers of shortUrl=%s", shortUrl)
+	referrers, err := s.queries.FindTopReferrers(c, repository.FindTopReferrersParams{
+		UrlID:     url.ID,
+		StartedAt: param.From,
+		EndedAt:   param.To,
+		TopLimit:  param.Limit,
+	})
+	if err != nil {
+		err = fmt.Errorf(
+			"failed finding top referrers of shortUrl=%s with error=%w",
+			shortUrl,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return Stats{}, err
+	}
+	for _, referrer := range referrers {
+		stats.TopReferrers = append(
+			stats.TopReferrers,
+			TopEntry{Value: referrer.Referrer, Clicks: referrer.Clicks},
+		)
+	}
+	logger.Info().Msgf("found top referrers of shortUrl=%s", shortUrl)
+
+	logger.Info().Msgf("finding top user agents of shortUrl=%s", shortUrl)
+	families, err := s.queries.FindTopUserAgentFamilies(
+		c,
+		repository.FindTopUserAgentFamiliesParams{
+			UrlID:     url.ID,
+			StartedAt: param.From,
+			EndedAt:   param.To,
+			TopLimit:  param.Limit,
+		},
+	)
+	if err != nil {
+		err = fmt.Errorf(
+			"failed finding top user agents of shortUrl=%s with error=%w",
+			shortUrl,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return Stats{}, err
+	}
+	for _, family := range families {
+		stats.TopUserAgents = append(
+			stats.TopUserAgents,
+			TopEntry{Value: family.UserAgentFamily, Clicks: family.Clicks},
+		)
+	}
+	logger.Info().Msgf("found top user agents of shortUrl=%s", shortUrl)
+
+	return stats, nil
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+	"github.com/Alturino/url-shortener/internal/transfer"
+)
+
+const (
+	exportBatchSize     = 1000
+	importCacheChunkLen = 500
+)
+
+type ImportStrategy string
+
+const (
+	ImportSkip      ImportStrategy = "skip"
+	ImportOverwrite ImportStrategy = "overwrite"
+	ImportFail      ImportStrategy = "fail"
+)
+
+func ParseImportStrategy(strategy string) (ImportStrategy, error) {
+	switch ImportStrategy(strategy) {
+	case "":
+		return ImportFail, nil
+	case ImportSkip, ImportOverwrite, ImportFail:
+		return ImportStrategy(strategy), nil
+	}
+	return "", fmt.Errorf(
+		"on_conflict=%s must be one of skip, overwrite or fail with error=%w",
+		strategy,
+		ErrInvalidImport,
+	)
+}
+
+// ImportResult counts the imported urls, Updated is only set by overwrite
+// and Skipped only by skip.
+type ImportResult struct {
+	Strategy ImportStrategy `json:"strategy"`
+	Inserted int            `json:"inserted"`
+	Updated  int            `json:"updated"`
+	Skipped  int            `json:"skipped"`
+}
+
+func isUniqueViolation(err error) bool {
+	pqErr := &pq.Error{}
+	return errors.As(err, &pqErr) && pqErr.Code == codeUniqueViolation
+}
+
+// ExportUrls writes every url to w ordered by id, the urls are read in keyset
+// batches and w is flushed after each one so memory does not grow with the
+// number of urls.
+func (s *UrlService) ExportUrls(c context.Context, w transfer.Writer) (int, error) {
+	c, span := tracer.Start(c, "UrlService ExportUrls")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	err := requireAdmin(c)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return 0, err
+	}
+
+	exported := 0
+	afterID := uuid.Nil
+	for {
+		urls, err := s.queries.ExportUrls(c, repository.ExportUrlsParams{
+			AfterID:   afterID,
+			BatchSize: exportBatchSize,
+		})
+		if err != nil {
+			err = fmt.Errorf("failed exporting urls after id=%s with error=%w", afterID, err)
+			logger.Error().Err(err).Msg(err.Error())
+			return exported, err
+		}
+
+		for _, url := range urls {
+			err = w.Write(url)
+			if err != nil {
+				err = fmt.Errorf("failed writing url id=%s with error=%w", url.ID, err)
+				logger.Error().Err(err).Msg(err.Error())
+				return exported, err
+			}
+		}
+		exported += len(urls)
+
+		err = w.Flush()
+		if err != nil {
+			err = fmt.Errorf("failed flushing export with error=%w", err)
+			logger.Error().Err(err).Msg(err.Error())
+			return exported, err
+		}
+		logger.Info().Msgf("exported %d urls", exported)
+
+		if len(urls) < exportBatchSize {
+			return exported, nil
+		}
+		afterID = urls[len(urls)-1].ID
+	}
+}
+
+// ImportUrls inserts the urls of r in a single transaction keeping their id,
+// short url, counts and timestamps. A short url that already exists is left
+// alone by skip, replaced by overwrite and aborts the whole import by fail.
+// The imported urls are written to the cache after the commit, a cache
+// failure is only logged.
+func (s *UrlService) ImportUrls(
+	c context.Context,
+	r transfer.Reader,
+	strategy ImportStrategy,
+) (ImportResult, error) {
+	c, span := tracer.Start(c, "UrlService ImportUrls")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Str("strategy", string(strategy)).Logger()
+
+	err := requireAdmin(c)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return ImportResult{}, err
+	}
+
+	logger.Info().Msg("beginning import transaction")
+	tx, err := s.db.BeginTx(c, nil)
+	if err != nil {
+		err = fmt.Errorf("failed beginning import transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return ImportResult{}, err
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.Error().
+				Err(err).
+				Msgf("failed rolling back import transaction with error=%s", err)
+		}
+	}()
+	queries := s.queries.WithTx(tx)
+
+	result := ImportResult{Strategy: strategy}
+	imported := []string{}
+	now := time.Now()
+	for record := 1; ; record++ {
+		url, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Error().Err(err).Msg(err.Error())
+			return ImportResult{}, err
+		}
+
+		param, err := s.importUrlParams(c, url, now)
+		if err != nil {
+			err = fmt.Errorf("invalid url at record=%d with error=%w", record, err)
+			logger.Error().Err(err).Msg(err.Error())
+			return ImportResult{}, err
+		}
+
+		// before is the replaced url of an overwrite, skipped is set when a
+		// skip import left an existing url alone
+		var before *repository.Url
+		var after repository.Url
+		skipped := false
+		switch strategy {
+		case ImportSkip:
+			after, err = queries.ImportUrlIfNotExists(
+				c,
+				repository.ImportUrlIfNotExistsParams(param),
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				skipped, err = true, nil
+			}
+		case ImportOverwrite:
+			before, after, err = importOverwrite(c, queries, param)
+		default:
+			after, err = queries.ImportUrl(c, param)
+		}
+		if isUniqueViolation(err) {
+			err = fmt.Errorf(
+				"shortUrl=%s or id=%s at record=%d already exists with error=%w: %w",
+				param.ShortUrl,
+				param.ID,
+				record,
+				ErrImportConflict,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			return ImportResult{}, err
+		}
+		if err != nil {
+			err = fmt.Errorf(
+				"failed importing shortUrl=%s at record=%d with error=%w",
+				param.ShortUrl,
+				record,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			return ImportResult{}, err
+		}
+
+		switch {
+		case skipped:
+			result.Skipped++
+			continue
+		case before != nil:
+			result.Updated++
+		default:
+			result.Inserted++
+		}
+
+		err = audit(c, queries, AuditActionImport, after.ShortUrl, before, after)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		imported = append(imported, param.ShortUrl)
+		if record%exportBatchSize == 0 {
+			logger.Info().Msgf("imported %d records", record)
+		}
+	}
+
+	logger.Info().Msgf("committing import transaction of %d urls", len(imported))
+	err = tx.Commit()
+	if err != nil {
+		err = fmt.Errorf("failed committing import transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return ImportResult{}, err
+	}
+	logger.Info().Msgf("committed import transaction of %d urls", len(imported))
+
+	s.cacheImported(c, imported)
+	return result, nil
+}
+
+// importUrlParams validates url like InsertUrl does and fills the fields an
+// import may leave out, a missing id is generated, a missing created_at is now
+// and a missing updated_at is created_at. The short url must be a valid alias
+// of any length up to the maximum so it cannot shadow a route.
+func (s *UrlService) importUrlParams(
+	c context.Context,
+	url repository.Url,
+	now time.Time,
+) (repository.ImportUrlParams, error) {
+	if url.Url == "" || url.ShortUrl == "" {
+		return repository.ImportUrlParams{}, fmt.Errorf(
+			"url and short_url are required with error=%w",
+			ErrInvalidImport,
+		)
+	}
+	if url.VisitedCount < 0 {
+		return repository.ImportUrlParams{}, fmt.Errorf(
+			"visited_count=%d must not be negative with error=%w",
+			url.VisitedCount,
+			ErrInvalidImport,
+		)
+	}
+	if url.RedirectStatus == 0 {
+		url.RedirectStatus = defaultRedirectStatus
+	}
+
+	err := validateAlias(url.ShortUrl, 1, maxShortUrlLength)
+	if err == nil {
+		err = validateRedirectStatus(url.RedirectStatus)
+	}
+	if err == nil {
+		// exported urls may already be expired or past their activation
+		// window, only the click budget and the order of the window are checked
+		err = validateExpiration(nil, url.MaxClicks, now)
+	}
+	if err == nil {
+		err = validateActivation(url.ActiveFrom, url.ActiveUntil, time.Time{})
+	}
+	if err == nil {
+		var destination *neturl.URL
+		destination, err = s.destinations.Validate(url.Url)
+		if err == nil {
+			url.Url = destination.String()
+			err = s.checkPolicy(c, destination)
+		}
+	}
+	if err != nil {
+		return repository.ImportUrlParams{}, fmt.Errorf(
+			"shortUrl=%s is not valid with error=%w: %w",
+			url.ShortUrl,
+			ErrInvalidImport,
+			err,
+		)
+	}
+
+	if url.ID == uuid.Nil {
+		url.ID = uuid.New()
+	}
+	if url.CreatedAt.IsZero() {
+		url.CreatedAt = now
+	}
+	if url.UpdatedAt.IsZero() {
+		url.UpdatedAt = url.CreatedAt
+	}
+
+	return repository.ImportUrlParams{
+		ID:             url.ID,
+		Url:            url.Url,
+		ShortUrl:       url.ShortUrl,
+		CreatedAt:      url.CreatedAt,
+		UpdatedAt:      url.UpdatedAt,
+		VisitedCount:   url.VisitedCount,
+		RedirectStatus: url.RedirectStatus,
+		ExpiresAt:      url.ExpiresAt,
+		MaxClicks:      url.MaxClicks,
+		ActiveFrom:     url.ActiveFrom,
+		ActiveUntil:    url.ActiveUntil,
+		OwnerID:        url.OwnerID,
+	}, nil
+}
+
+// importOverwrite imports param replacing the url with the same short url and
+// returns the replaced url, nil when param was inserted. A replaced
+// destination is recorded as an import revision of the url. A deleted url is
+// a conflict, it is only brought back by RestoreUrl.
+func importOverwrite(
+	c context.Context,
+	queries *repository.Queries,
+	param repository.ImportUrlParams,
+) (*repository.Url, repository.Url, error) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	existing, err := queries.FindUrlByShortUrlForUpdate(c, param.ShortUrl)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("failed finding shortUrl=%s with error=%w", param.ShortUrl, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return nil, repository.Url{}, err
+	}
+	if err == nil && existing.DeletedAt != nil {
+		err = fmt.Errorf(
+			"shortUrl=%s is deleted with error=%w",
+			param.ShortUrl,
+			ErrImportConflict,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return nil, repository.Url{}, err
+	}
+
+	row, err := queries.ImportUrlOverwrite(c, repository.ImportUrlOverwriteParams(param))
+	if err != nil {
+		return nil, repository.Url{}, err
+	}
+	imported := overwrittenUrl(row)
+	if row.Inserted {
+		return nil, imported, nil
+	}
+
+	if existing.Url != imported.Url {
+		_, err = recordRevision(c, queries, existing, imported, RevisionActionImport, nil)
+		if err != nil {
+			return nil, repository.Url{}, err
+		}
+	}
+	return &existing, imported, nil
+}
+
+func overwrittenUrl(row repository.ImportUrlOverwriteRow) repository.Url {
+	return repository.Url{
+		ID:              row.ID,
+		Url:             row.Url,
+		ShortUrl:        row.ShortUrl,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+		VisitedCount:    row.VisitedCount,
+		RedirectStatus:  row.RedirectStatus,
+		ExpiresAt:       row.ExpiresAt,
+		MaxClicks:       row.MaxClicks,
+		ActiveFrom:      row.ActiveFrom,
+		ActiveUntil:     row.ActiveUntil,
+		OwnerID:         row.OwnerID,
+		DestinationHost: row.DestinationHost,
+		DeletedAt:       row.DeletedAt,
+	}
+}
+
+// cacheImported reloads the imported urls in chunks and writes them to the
+// cache, which also clears their cached not found entries.
+func (s *UrlService) cacheImported(c context.Context, shortUrls []string) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msgf("inserting %d imported urls to cache", len(shortUrls))
+	for start := 0; start < len(shortUrls); start += importCacheChunkLen {
+		end := min(start+importCacheChunkLen, len(shortUrls))
+		urls, err := s.queries.FindUrlsByShortUrls(c, shortUrls[start:end])
+		if err == nil {
+			err = s.cache.SetMany(c, urls)
+		}
+		if err != nil {
+			err = fmt.Errorf(
+				"failed inserting %d imported urls to cache with error=%w",
+				end-start,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			return
+		}
+	}
+	logger.Info().Msgf("inserted %d imported urls to cache", len(shortUrls))
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+func rollbackTx(c context.Context, tx *sql.Tx) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		zerolog.Ctx(c).Error().Err(err).Msgf("failed rolling back transaction with error=%s", err)
+	}
+}
+
+// commitWithCache commits tx and writes url to the cache after, the database
+// is the source of truth so a cache failure is only logged and the entry is
+// dropped for the next read to load it from the database.
+func (s *UrlService) commitWithCache(c context.Context, tx *sql.Tx, url repository.Url) error {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msgf("committing shortUrl=%s", url.ShortUrl)
+	err := tx.Commit()
+	if err != nil {
+		err = fmt.Errorf("failed committing shortUrl=%s with error=%w", url.ShortUrl, err)
+		logger.Error().Err(err).Msg(err.Error())
+		return err
+	}
+	logger.Info().Msgf("committed shortUrl=%s", url.ShortUrl)
+
+	logger.Info().Msgf("updating shortUrl=%s url=%s to cache", url.ShortUrl, url.Url)
+	err = s.cache.Set(c, url)
+	if err != nil {
+		err = fmt.Errorf(
+			"failed updating shortUrl=%s url=%s to cache with error=%w",
+			url.ShortUrl,
+			url.Url,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+
+		err = s.cache.Delete(c, url.ShortUrl)
+		if err != nil {
+			err = fmt.Errorf(
+				"failed deleting stale shortUrl=%s from cache with error=%w",
+				url.ShortUrl,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+		}
+		return nil
+	}
+	logger.Info().Msgf("updated shortUrl=%s url=%s to cache", url.ShortUrl, url.Url)
+
+	return nil
+}
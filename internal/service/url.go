@@ -3,50 +3,238 @@ package service
 import (
 	"context"
 	"database/sql"
-	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/url"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/redis/go-redis/v9"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/Alturino/url-shortener/internal/cache"
+	"github.com/Alturino/url-shortener/internal/config"
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
+	"github.com/Alturino/url-shortener/internal/generator"
 	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/policy"
 	"github.com/Alturino/url-shortener/internal/repository"
+	"github.com/Alturino/url-shortener/internal/validation"
 )
 
 const name = "github.com/Alturino/url-shortener"
 
 var tracer = otel.Tracer(name)
 
+const (
+	codeUniqueViolation      = "23505"
+	constraintShortUrlUnique = "urls_short_url_key"
+)
+
 type UrlService struct {
-	cache   *redis.Client
-	db      *sql.DB
-	encoder *base64.Encoding
-	queries *repository.Queries
+	cache     cache.UrlCache
+	db        *sql.DB
+	generator generator.ShortCodeGenerator
+	queries   *repository.Queries
+	visits    *VisitCounter
+	clicks    *ClickRecorder
+	visitors  *UniqueVisitorCounter
+	group     singleflight.Group
+
+	destinations    *validation.DestinationValidator
+	policy          *policy.Engine
+	policyConfig    config.Policy
+	batchConfig     config.Batch
+	shortCodeConfig config.ShortCode
 }
 
 func NewUrlService(
-	cache *redis.Client,
+	cache cache.UrlCache,
 	db *sql.DB,
-	encoder *base64.Encoding,
+	generator generator.ShortCodeGenerator,
 	queries *repository.Queries,
+	visits *VisitCounter,
+	clicks *ClickRecorder,
+	visitors *UniqueVisitorCounter,
+	destinations *validation.DestinationValidator,
+	policy *policy.Engine,
+	policyConfig config.Policy,
+	batchConfig config.Batch,
+	shortCodeConfig config.ShortCode,
 ) *UrlService {
-	return &UrlService{cache: cache, db: db, queries: queries, encoder: encoder}
+	if shortCodeConfig.MaxRetries <= 0 {
+		shortCodeConfig.MaxRetries = 1
+	}
+	if batchConfig.MaxItems <= 0 {
+		batchConfig.MaxItems = DefaultBatchMaxItems
+	}
+	return &UrlService{
+		cache:           cache,
+		db:              db,
+		queries:         queries,
+		visits:          visits,
+		clicks:          clicks,
+		visitors:        visitors,
+		generator:       generator,
+		destinations:    destinations,
+		policy:          policy,
+		policyConfig:    policyConfig,
+		batchConfig:     batchConfig,
+		shortCodeConfig: shortCodeConfig,
+	}
+}
+
+type InsertUrlParams struct {
+	Url            string
+	Alias          string
+	RedirectStatus int16
+	ExpiresAt      *time.Time
+	MaxClicks      *int32
+	ActiveFrom     *time.Time
+	ActiveUntil    *time.Time
+}
+
+// UpdateUrlParams keeps the expiration and activation fields that are nil,
+// the Clear fields reset them to null instead.
+type UpdateUrlParams struct {
+	Url              string
+	RedirectStatus   int16
+	ExpiresAt        *time.Time
+	MaxClicks        *int32
+	ActiveFrom       *time.Time
+	ActiveUntil      *time.Time
+	ClearExpiresAt   bool
+	ClearMaxClicks   bool
+	ClearActiveFrom  bool
+	ClearActiveUntil bool
+}
+
+func isShortUrlConflict(err error) bool {
+	pqErr := &pq.Error{}
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == codeUniqueViolation && pqErr.Constraint == constraintShortUrlUnique
 }
 
 func (s *UrlService) InsertUrl(
 	c context.Context,
-	param url.URL,
+	param InsertUrlParams,
 ) (repository.Url, error) {
 	c, span := tracer.Start(c, "UrlService InsertUrl")
 	defer span.End()
 
 	logger := zerolog.Ctx(c).With().Logger()
 
+	principal, err := requirePrincipal(c)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	ownerID := uuid.NullUUID{UUID: principal.OwnerID, Valid: true}
+
+	param, err = s.validateInsertUrl(c, param)
+	if err != nil {
+		return repository.Url{}, err
+	}
+
+	logger.Info().Msg("beginning insert transaction")
+	tx, err := s.db.BeginTx(c, nil)
+	if err != nil {
+		err = fmt.Errorf("failed beginning insert transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	defer rollbackTx(c, tx)
+	queries := s.queries.WithTx(tx)
+
+	inserted, err := s.insertUrl(c, ownerID, param, savepointInsert(tx, queries))
+	if err != nil {
+		return repository.Url{}, err
+	}
+	id, shortUrl := inserted.ID, inserted.ShortUrl
+	logger.Info().
+		Str(log.KeyUrlID, inserted.ID.String()).
+		Msgf("inserted url=%s id=%s shortUrl=%s", param.Url, id, shortUrl)
+
+	err = audit(c, queries, AuditActionCreate, shortUrl, nil, inserted)
+	if err != nil {
+		return repository.Url{}, err
+	}
+
+	err = s.commitWithCache(c, tx, inserted)
+	if err != nil {
+		return repository.Url{}, err
+	}
+	return inserted, nil
+}
+
+// validateInsertUrl validates and normalizes param before it is inserted.
+func (s *UrlService) validateInsertUrl(
+	c context.Context,
+	param InsertUrlParams,
+) (InsertUrlParams, error) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	logger.Info().Msgf("validating destination url=%s", param.Url)
+	destination, err := s.destinations.Validate(param.Url)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return param, err
+	}
+	param.Url = destination.String()
+	logger.Info().Msgf("validated destination url=%s", param.Url)
+
+	err = s.checkPolicy(c, destination)
+	if err != nil {
+		return param, err
+	}
+
+	if param.RedirectStatus == 0 {
+		param.RedirectStatus = defaultRedirectStatus
+	}
+	logger.Info().Msgf("validating redirectStatus=%d", param.RedirectStatus)
+	err = validateRedirectStatus(param.RedirectStatus)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return param, err
+	}
+	logger.Info().Msgf("validated redirectStatus=%d", param.RedirectStatus)
+
+	logger.Info().Msg("validating expiration")
+	err = validateExpiration(param.ExpiresAt, param.MaxClicks, time.Now())
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return param, err
+	}
+	logger.Info().Msg("validated expiration")
+
+	logger.Info().Msg("validating activation window")
+	err = validateActivation(param.ActiveFrom, param.ActiveUntil, time.Now())
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return param, err
+	}
+	logger.Info().Msg("validated activation window")
+
+	return param, nil
+}
+
+// insertFunc inserts a single url, it is the queries of the database or of a
+// transaction.
+type insertFunc func(context.Context, repository.InsertUrlParams) (repository.Url, error)
+
+// insertUrl inserts param with its alias or a generated shortUrl through
+// insert.
+func (s *UrlService) insertUrl(
+	c context.Context,
+	ownerID uuid.NullUUID,
+	param InsertUrlParams,
+	insert insertFunc,
+) (repository.Url, error) {
+	logger := zerolog.Ctx(c).With().Logger()
+
 	logger.Info().Msg("generating uuid")
 	id, err := uuid.NewRandom()
 	if err != nil {
@@ -55,63 +243,221 @@ func (s *UrlService) InsertUrl(
 	}
 	logger.Info().Msgf("generated uuid=%s", id.String())
 
-	logger.Info().Msgf("starting to encode url=%s id=%s to shortUrl", param.String(), id.String())
-	encoded := s.encoder.EncodeToString([]byte(id.String()))
-	shortUrl := encoded[:5]
-	logger.Info().Msgf("encoded url=%s id=%s to shortUrl=%s", param.String(), id.String(), shortUrl)
+	if param.Alias != "" {
+		return s.insertAlias(c, id, ownerID, param, insert)
+	}
+	return s.insertGenerated(c, id, ownerID, param, insert)
+}
 
-	logger.Info().Msgf("inserting url=%s id=%s shortUrl=%s", param.String(), id.String(), shortUrl)
-	inserted, err := s.queries.InsertUrl(c, repository.InsertUrlParams{
-		ID:       id,
-		Url:      param.String(),
-		ShortUrl: shortUrl,
-	})
+// savepointInsert inserts through queries of tx inside a savepoint, a failed
+// insert is rolled back to it so the transaction can go on.
+func savepointInsert(tx *sql.Tx, queries *repository.Queries) insertFunc {
+	return func(c context.Context, param repository.InsertUrlParams) (repository.Url, error) {
+		_, err := tx.ExecContext(c, "savepoint batch_item")
+		if err != nil {
+			return repository.Url{}, fmt.Errorf("failed creating savepoint with error=%w", err)
+		}
+
+		inserted, err := queries.InsertUrl(c, param)
+		if err != nil {
+			_, rollbackErr := tx.ExecContext(c, "rollback to savepoint batch_item")
+			if rollbackErr != nil {
+				return repository.Url{}, fmt.Errorf(
+					"failed rolling back to savepoint with error=%w",
+					errors.Join(err, rollbackErr),
+				)
+			}
+			return repository.Url{}, err
+		}
+
+		_, err = tx.ExecContext(c, "release savepoint batch_item")
+		if err != nil {
+			return repository.Url{}, fmt.Errorf("failed releasing savepoint with error=%w", err)
+		}
+		return inserted, nil
+	}
+}
+
+func (s *UrlService) insertAlias(
+	c context.Context,
+	id uuid.UUID,
+	ownerID uuid.NullUUID,
+	param InsertUrlParams,
+	insert insertFunc,
+) (repository.Url, error) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyAlias, param.Alias).Logger()
+
+	logger.Info().Msgf("validating alias=%s", param.Alias)
+	err := validateAlias(
+		param.Alias,
+		s.shortCodeConfig.AliasMinLength,
+		s.shortCodeConfig.AliasMaxLength,
+	)
 	if err != nil {
-		err = fmt.Errorf(
-			"failed when inserting url=%s with id=%s to database with error=%w",
-			param.String(),
-			id.String(),
-			err,
-		)
 		logger.Error().Err(err).Msg(err.Error())
 		return repository.Url{}, err
 	}
-	logger.Info().
-		Str(log.KeyUrlID, inserted.ID.String()).
-		Msgf("inserted url=%s id=%s shortUrl=%s", param.String(), id, shortUrl)
+	logger.Info().Msgf("validated alias=%s", param.Alias)
 
 	logger.Info().
-		Msgf("inserting shortUrl=%s url=%s id=%s to cache", shortUrl, param.String(), id.String())
-	err = s.cache.JSONSet(c, fmt.Sprintf(cache.KeyUrl, shortUrl), "$", inserted).Err()
+		Msgf("inserting url=%s id=%s shortUrl=%s", param.Url, id.String(), param.Alias)
+	inserted, err := insert(c, repository.InsertUrlParams{
+		ID:             id,
+		Url:            param.Url,
+		ShortUrl:       param.Alias,
+		RedirectStatus: param.RedirectStatus,
+		ExpiresAt:      param.ExpiresAt,
+		MaxClicks:      param.MaxClicks,
+		ActiveFrom:     param.ActiveFrom,
+		ActiveUntil:    param.ActiveUntil,
+		OwnerID:        ownerID,
+	})
+	if isShortUrlConflict(err) {
+		err = fmt.Errorf("alias=%s is already taken with error=%w", param.Alias, ErrShortUrlConflict)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
 	if err != nil {
 		err = fmt.Errorf(
-			"inserting shortUrl=%s url=%s id=%s to cache with error=%w",
-			shortUrl,
-			param.String(),
+			"failed when inserting url=%s with id=%s to database with error=%w",
+			param.Url,
 			id.String(),
 			err,
 		)
 		logger.Error().Err(err).Msg(err.Error())
-		return inserted, err
+		return repository.Url{}, err
 	}
-	logger.Info().
-		Msgf("inserting shortUrl=%s url=%s id=%s to cache", shortUrl, param.String(), id.String())
-
 	return inserted, nil
 }
 
+func (s *UrlService) insertGenerated(
+	c context.Context,
+	id uuid.UUID,
+	ownerID uuid.NullUUID,
+	param InsertUrlParams,
+	insert insertFunc,
+) (repository.Url, error) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	for attempt := 1; attempt <= s.shortCodeConfig.MaxRetries; attempt++ {
+		logger.Info().
+			Msgf("generating shortUrl for url=%s id=%s attempt=%d", param.Url, id, attempt)
+		shortUrl, err := s.generator.Generate(c)
+		if err != nil {
+			err = fmt.Errorf(
+				"failed generating shortUrl for url=%s with error=%w",
+				param.Url,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			return repository.Url{}, err
+		}
+		if isReservedAlias(shortUrl) {
+			logger.Warn().Msgf("generated shortUrl=%s is reserved retrying", shortUrl)
+			continue
+		}
+		logger.Info().
+			Msgf("generated shortUrl=%s for url=%s id=%s", shortUrl, param.Url, id)
+
+		logger.Info().
+			Msgf("inserting url=%s id=%s shortUrl=%s", param.Url, id.String(), shortUrl)
+		inserted, err := insert(c, repository.InsertUrlParams{
+			ID:             id,
+			Url:            param.Url,
+			ShortUrl:       shortUrl,
+			RedirectStatus: param.RedirectStatus,
+			ExpiresAt:      param.ExpiresAt,
+			MaxClicks:      param.MaxClicks,
+			ActiveFrom:     param.ActiveFrom,
+			ActiveUntil:    param.ActiveUntil,
+			OwnerID:        ownerID,
+		})
+		if err == nil {
+			return inserted, nil
+		}
+		if !isShortUrlConflict(err) {
+			err = fmt.Errorf(
+				"failed when inserting url=%s with id=%s to database with error=%w",
+				param.Url,
+				id.String(),
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			return repository.Url{}, err
+		}
+		logger.Warn().
+			Err(err).
+			Msgf("shortUrl=%s already exists retrying attempt=%d", shortUrl, attempt)
+	}
+
+	err := fmt.Errorf(
+		"failed generating unique shortUrl for url=%s after %d attempts with error=%w",
+		param.Url,
+		s.shortCodeConfig.MaxRetries,
+		ErrShortUrlConflict,
+	)
+	logger.Error().Err(err).Msg(err.Error())
+	return repository.Url{}, err
+}
+
 func (s *UrlService) UpdateUrl(
 	c context.Context,
-	url url.URL,
 	shortUrl string,
+	param UpdateUrlParams,
 ) (repository.Url, error) {
 	c, span := tracer.Start(c, "UrlService UpdateUrl")
 	defer span.End()
 
 	logger := zerolog.Ctx(c).With().Logger()
 
+	logger.Info().Msgf("validating destination url=%s", param.Url)
+	url, err := s.destinations.Validate(param.Url)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	logger.Info().Msgf("validated destination url=%s", url.String())
+
+	err = s.checkPolicy(c, url)
+	if err != nil {
+		return repository.Url{}, err
+	}
+
+	redirectStatus := sql.NullInt16{}
+	if param.RedirectStatus != 0 {
+		logger.Info().Msgf("validating redirectStatus=%d", param.RedirectStatus)
+		err := validateRedirectStatus(param.RedirectStatus)
+		if err != nil {
+			logger.Error().Err(err).Msg(err.Error())
+			return repository.Url{}, err
+		}
+		redirectStatus = sql.NullInt16{Int16: param.RedirectStatus, Valid: true}
+		logger.Info().Msgf("validated redirectStatus=%d", param.RedirectStatus)
+	}
+
+	logger.Info().Msg("validating expiration")
+	err = validateCleared(param)
+	if err == nil {
+		err = validateExpiration(param.ExpiresAt, param.MaxClicks, time.Now())
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	logger.Info().Msg("validated expiration")
+
+	logger.Info().Msg("beginning update transaction")
+	tx, err := s.db.BeginTx(c, nil)
+	if err != nil {
+		err = fmt.Errorf("failed beginning update transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	defer rollbackTx(c, tx)
+	queries := s.queries.WithTx(tx)
+
 	logger.Info().Msgf("finding shortUrl=%s", shortUrl)
-	existing, err := s.queries.FindUrlByShortUrl(c, shortUrl)
+	existing, err := queries.FindUrlByShortUrlForUpdate(c, shortUrl)
 	if err != nil {
 		logger.Error().
 			Err(err).
@@ -124,11 +470,58 @@ func (s *UrlService) UpdateUrl(
 		Logger()
 	logger.Info().Msgf("found shortUrl=%s", shortUrl)
 
+	err = authorizeOwner(c, existing)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+
+	err = checkDeleted(existing)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+
+	if param.ActiveFrom != nil || param.ActiveUntil != nil {
+		activeFrom, activeUntil := existing.ActiveFrom, existing.ActiveUntil
+		if param.ActiveFrom != nil {
+			activeFrom = param.ActiveFrom
+		}
+		if param.ActiveUntil != nil {
+			activeUntil = param.ActiveUntil
+		}
+		if param.ClearActiveFrom {
+			activeFrom = nil
+		}
+		if param.ClearActiveUntil {
+			activeUntil = nil
+		}
+		logger.Info().Msg("validating activation window")
+		err = validateActivation(activeFrom, activeUntil, time.Now())
+		if err != nil {
+			logger.Error().Err(err).Msg(err.Error())
+			return repository.Url{}, err
+		}
+		logger.Info().Msg("validated activation window")
+	}
+
 	logger.Info().
 		Msgf("updating url=%s id=%s to url=%s", existing.Url, existing.ID.String(), url.String())
-	updated, err := s.queries.UpdateUrl(
+	updated, err := queries.UpdateUrl(
 		c,
-		repository.UpdateUrlParams{ShortUrl: shortUrl, Url: url.String()},
+		repository.UpdateUrlParams{
+			ShortUrl:         shortUrl,
+			Url:              url.String(),
+			RedirectStatus:   redirectStatus,
+			ExpiresAt:        param.ExpiresAt,
+			MaxClicks:        param.MaxClicks,
+			ActiveFrom:       param.ActiveFrom,
+			ActiveUntil:      param.ActiveUntil,
+			ClearExpiresAt:   param.ClearExpiresAt,
+			ClearMaxClicks:   param.ClearMaxClicks,
+			ClearActiveFrom:  param.ClearActiveFrom,
+			ClearActiveUntil: param.ClearActiveUntil,
+		},
 	)
 	if err != nil {
 		logger.Error().
@@ -139,23 +532,22 @@ func (s *UrlService) UpdateUrl(
 	logger.Info().
 		Msgf("updated url=%s id=%s to url=%s", existing.Url, existing.ID.String(), url.String())
 
-	logger.Info().
-		Msgf("updating shortUrl=%s url=%s id=%s to cache", shortUrl, url.String(), existing.ID.String())
-	err = s.cache.JSONSet(c, fmt.Sprintf(cache.KeyUrl, shortUrl), "$", updated).Err()
+	if updated.Url != existing.Url {
+		_, err = recordRevision(c, queries, existing, updated, RevisionActionUpdate, nil)
+		if err != nil {
+			return repository.Url{}, err
+		}
+	}
+
+	err = audit(c, queries, AuditActionUpdate, shortUrl, existing, updated)
 	if err != nil {
-		err = fmt.Errorf(
-			"failed updating shortUrl=%s url=%s id=%s to cache with error=%w",
-			shortUrl,
-			url.String(),
-			existing.ID.String(),
-			err,
-		)
-		logger.Error().Err(err).Msg(err.Error())
-		return updated, err
+		return repository.Url{}, err
 	}
-	logger.Info().
-		Msgf("updated shortUrl=%s url=%s id=%s to cache", shortUrl, url.String(), existing.ID.String())
 
+	err = s.commitWithCache(c, tx, updated)
+	if err != nil {
+		return repository.Url{}, err
+	}
 	return updated, nil
 }
 
@@ -168,87 +560,147 @@ func (s *UrlService) DeleteUrl(
 
 	logger := zerolog.Ctx(c).With().Logger()
 
+	logger.Info().Msg("beginning delete transaction")
+	tx, err := s.db.BeginTx(c, nil)
+	if err != nil {
+		err = fmt.Errorf("failed beginning delete transaction with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	defer rollbackTx(c, tx)
+	queries := s.queries.WithTx(tx)
+
+	logger.Info().Msgf("finding shortUrl=%s", shortUrl)
+	existing, err := queries.FindUrlByShortUrlForUpdate(c, shortUrl)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msgf("shortUrl=%s not found", shortUrl)
+		return repository.Url{}, err
+	}
+	logger.Info().Msgf("found shortUrl=%s", shortUrl)
+
+	err = authorizeOwner(c, existing)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+
+	err = checkDeleted(existing)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+
 	logger.Info().Msgf("deleting shortUrl=%s", shortUrl)
-	deleted, err := s.queries.DeleteUrlByShortUrl(c, shortUrl)
+	deleted, err := queries.SoftDeleteUrlByShortUrl(c, shortUrl)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("shortUrl=%s is already deleted with error=%w", shortUrl, ErrUrlDeleted)
+	}
 	if err != nil {
 		logger.Error().
 			Err(err).
-			Msgf("failed deleting shortUrl=%s not found", shortUrl)
+			Msgf("failed deleting shortUrl=%s with error=%s", shortUrl, err.Error())
 		return repository.Url{}, err
 	}
 	logger.Info().Msgf("deleted url=%s id=%s", deleted.Url, deleted.ID.String())
 
-	logger.Info().Msgf("deleting shortUrl=%s from cache", shortUrl)
-	err = s.cache.JSONDel(c, fmt.Sprintf(cache.KeyUrl, shortUrl), "$").Err()
+	err = audit(c, queries, AuditActionDelete, shortUrl, existing, deleted)
 	if err != nil {
-		err = fmt.Errorf("failed deleting shortUrl=%s from cache with error=%w", shortUrl, err)
-		logger.Error().Err(err).Msg(err.Error())
-		return deleted, err
+		return repository.Url{}, err
 	}
-	logger.Info().Msgf("deleted shortUrl=%s from cache", shortUrl)
 
+	err = s.commitWithCache(c, tx, deleted)
+	if err != nil {
+		return repository.Url{}, err
+	}
 	return deleted, nil
 }
 
 func (s *UrlService) GetUrlByShortUrl(
 	c context.Context,
 	shortUrl string,
+	visit Visit,
 ) (repository.Url, error) {
 	c, span := tracer.Start(c, "UrlService GetUrlByShortUrl")
 	defer span.End()
 
 	logger := zerolog.Ctx(c).With().Logger()
 
-	logger.Info().Msgf("incrementing visited_count for shortUrl=%s", shortUrl)
-	err := s.cache.JSONNumIncrBy(c, fmt.Sprintf(cache.KeyUrl, shortUrl), "$.visited_count", 1).Err()
+	updated, err := s.ResolveUrl(c, shortUrl)
 	if err != nil {
-		err = fmt.Errorf(
-			"failed incrementing visited_count for shortUrl=%s with error=%w",
-			shortUrl,
-			err,
-		)
-		logger.Error().Err(err).Msg(err.Error())
 		return repository.Url{}, err
 	}
-	logger.Info().Msgf("incremented visited_count for shortUrl=%s", shortUrl)
 
-	logger.Info().Msgf("finding shortUrl=%s from cache", shortUrl)
-	jsonCache, err := s.cache.JSONGet(c, fmt.Sprintf(cache.KeyUrl, shortUrl)).Result()
+	logger.Info().Msgf("counting visit for shortUrl=%s", shortUrl)
+	s.visits.Count(updated)
+	s.clicks.Record(c, Click{
+		UrlID:     updated.ID,
+		ShortUrl:  updated.ShortUrl,
+		ClickedAt: time.Now(),
+		Visit:     visit,
+	})
+	logger.Info().Msgf("counted visit for shortUrl=%s", shortUrl)
+
+	return updated, nil
+}
+
+// ResolveUrl returns the url shortUrl redirects to without counting a visit,
+// it fails with ErrUrlDeleted once the url is deleted, with ErrUrlBlocked
+// when the policy blocks its destination, with ErrUrlNotYetActive or
+// ErrUrlActivationEnded outside the activation window and with ErrUrlExpired
+// once the url reached its expiration time or click budget, also after the
+// reaper deleted it.
+func (s *UrlService) ResolveUrl(c context.Context, shortUrl string) (repository.Url, error) {
+	c, span := tracer.Start(c, "UrlService ResolveUrl")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	url, err := s.findUrl(c, shortUrl)
 	if err != nil {
-		err = fmt.Errorf("failed finding shortUrl=%s from cache with error=%w", shortUrl, err)
-		logger.Error().Err(err).Msg(err.Error())
 		return repository.Url{}, err
 	}
-	logger.Info().Msgf("found shortUrl=%s from cache", shortUrl)
 
-	updated := repository.Url{}
-	logger.Info().Msg("marshalling jsonCache to url struct")
-	err = json.Unmarshal([]byte(jsonCache), &updated)
+	now := time.Now()
+
+	err = checkDeleted(url)
 	if err != nil {
-		err = fmt.Errorf("failed marshalling jsonCache to url struct with error=%w", err)
-		logger.Error().Err(err).Msg(err.Error())
-		return repository.Url{}, err
+		// the reaper deletes expired urls, their tombstones keep answering as
+		// expired until they are purged
+		expiredErr := checkExpiration(url, s.visits.Pending(url.ID), now)
+		if expiredErr != nil {
+			err = expiredErr
+		}
+		logger.Info().Msg(err.Error())
+		return url, err
 	}
-	logger.Info().Msgf("marshalled jsonCache to url struct")
 
-	logger.Info().Msgf("updating visited_count for shortUrl=%s", shortUrl)
-	_, err = s.queries.UpdateVisitedCountUrl(
-		c,
-		repository.UpdateVisitedCountUrlParams{ID: updated.ID, VisitedCount: updated.VisitedCount},
-	)
-	logger.Info().
-		Msgf("updated visited_count for shortUrl=%s to %d", shortUrl, updated.VisitedCount)
+	logger.Info().Msgf("checking redirect policy of shortUrl=%s", shortUrl)
+	err = s.checkRedirectPolicy(url)
 	if err != nil {
-		err = fmt.Errorf(
-			"failed updating visited_count for shortUrl=%s with error=%w",
-			shortUrl,
-			err,
-		)
-		logger.Error().Err(err).Msg(err.Error())
-		return updated, nil
+		logger.Info().Msg(err.Error())
+		return url, err
 	}
+	logger.Info().Msgf("checked redirect policy of shortUrl=%s", shortUrl)
 
-	return updated, nil
+	logger.Info().Msgf("checking activation window of shortUrl=%s", shortUrl)
+	err = checkActivation(url, now)
+	if err != nil {
+		logger.Info().Msg(err.Error())
+		return url, err
+	}
+	logger.Info().Msgf("checked activation window of shortUrl=%s", shortUrl)
+
+	logger.Info().Msgf("checking expiration of shortUrl=%s", shortUrl)
+	err = checkExpiration(url, s.visits.Pending(url.ID), now)
+	if err != nil {
+		logger.Info().Msg(err.Error())
+		return url, err
+	}
+	logger.Info().Msgf("checked expiration of shortUrl=%s", shortUrl)
+
+	return url, nil
 }
 
 func (s *UrlService) GetUrlByShortUrlDetail(
@@ -258,35 +710,127 @@ func (s *UrlService) GetUrlByShortUrlDetail(
 	c, span := tracer.Start(c, "UrlService GetUrlByShortUrlDetail")
 	defer span.End()
 
+	url, err := s.findUrl(c, shortUrl)
+	if err != nil {
+		return repository.Url{}, err
+	}
+	err = checkDeleted(url)
+	if err != nil {
+		return repository.Url{}, err
+	}
+	return url, nil
+}
+
+// GetUrlMetadata returns shortUrl to its owner or an admin.
+func (s *UrlService) GetUrlMetadata(c context.Context, shortUrl string) (repository.Url, error) {
+	c, span := tracer.Start(c, "UrlService GetUrlMetadata")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	url, err := s.GetUrlByShortUrlDetail(c, shortUrl)
+	if err != nil {
+		return repository.Url{}, err
+	}
+	err = authorizeOwner(c, url)
+	if err != nil {
+		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	return url, nil
+}
+
+// findUrl reads shortUrl from the cache and falls back to the database when
+// the cache entry is missing, repopulating the cache on the way out.
+func (s *UrlService) findUrl(c context.Context, shortUrl string) (repository.Url, error) {
 	logger := zerolog.Ctx(c).With().Logger()
 
 	logger.Info().Msgf("finding shortUrl=%s from cache", shortUrl)
-	jsonCache, err := s.cache.JSONGet(c, fmt.Sprintf(cache.KeyUrl, shortUrl)).Result()
+	url, err := s.cache.Get(c, shortUrl)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		logger.Info().Msgf("shortUrl=%s not found in cache loading from database", shortUrl)
+		return s.loadUrl(c, shortUrl)
+	}
 	if err != nil {
-		err = fmt.Errorf("failed finding shortUrl=%s from cache with error=%w", shortUrl, err)
+		err = fmt.Errorf(
+			"failed finding shortUrl=%s from cache with error=%w: %w",
+			shortUrl,
+			apperrors.ErrUnavailable,
+			err,
+		)
 		logger.Error().Err(err).Msg(err.Error())
+		return repository.Url{}, err
+	}
+	logger.Info().Msgf("found shortUrl=%s from cache", shortUrl)
+
+	return url, nil
+}
 
-		logger.Info().Msgf("finding shortUrl=%s", shortUrl)
+// loadUrl collapses concurrent cache misses for the same shortUrl into a
+// single database query.
+func (s *UrlService) loadUrl(c context.Context, shortUrl string) (repository.Url, error) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	loaded, err, shared := s.group.Do(shortUrl, func() (interface{}, error) {
+		c := context.WithoutCancel(c)
+
+		logger.Info().Msgf("checking negative cache for shortUrl=%s", shortUrl)
+		notFound, err := s.cache.IsNotFound(c, shortUrl)
+		if err != nil {
+			err = fmt.Errorf(
+				"failed checking negative cache for shortUrl=%s with error=%w",
+				shortUrl,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+		}
+		if notFound {
+			err = fmt.Errorf(
+				"shortUrl=%s is cached as not found with error=%w",
+				shortUrl,
+				sql.ErrNoRows,
+			)
+			logger.Info().Msg(err.Error())
+			return repository.Url{}, err
+		}
+		logger.Info().Msgf("checked negative cache for shortUrl=%s", shortUrl)
+
+		logger.Info().Msgf("finding shortUrl=%s from database", shortUrl)
 		existing, err := s.queries.FindUrlByShortUrl(c, shortUrl)
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Info().Msgf("caching shortUrl=%s as not found", shortUrl)
+			cacheErr := s.cache.SetNotFound(c, shortUrl)
+			if cacheErr != nil {
+				cacheErr = fmt.Errorf(
+					"failed caching shortUrl=%s as not found with error=%w",
+					shortUrl,
+					cacheErr,
+				)
+				logger.Error().Err(cacheErr).Msg(cacheErr.Error())
+			}
+		}
 		if err != nil {
 			err = fmt.Errorf("failed finding shortUrl=%s with error=%w", shortUrl, err)
 			logger.Error().Err(err).Msg(err.Error())
 			return repository.Url{}, err
 		}
-		logger.Info().Any("existing", existing).Msgf("finding shortUrl=%s", shortUrl)
-		return existing, err
-	}
-	logger.Info().Msgf("found shortUrl=%s from cache", shortUrl)
+		logger.Info().Msgf("found shortUrl=%s from database", shortUrl)
 
-	logger.Info().Msg("marshalling jsonCache to url struct")
-	url := repository.Url{}
-	err = json.Unmarshal([]byte(jsonCache), &url)
+		logger.Info().Msgf("inserting shortUrl=%s to cache", shortUrl)
+		err = s.cache.Fill(c, existing)
+		if err != nil {
+			err = fmt.Errorf("failed inserting shortUrl=%s to cache with error=%w", shortUrl, err)
+			logger.Error().Err(err).Msg(err.Error())
+			return existing, nil
+		}
+		logger.Info().Msgf("inserted shortUrl=%s to cache", shortUrl)
+
+		return existing, nil
+	})
 	if err != nil {
-		err = fmt.Errorf("failed marshalling jsonCache to url struct with error=%w", err)
-		logger.Error().Err(err).Msg(err.Error())
 		return repository.Url{}, err
 	}
-	logger.Info().Msgf("marshalled jsonCache to url struct")
+	logger.Info().Bool(log.KeyShared, shared).Msgf("loaded shortUrl=%s", shortUrl)
 
-	return url, nil
+	return loaded.(repository.Url), nil
 }
@@ -0,0 +1,39 @@
+package service
+
+import "strings"
+
+type userAgentRule struct {
+	family   string
+	keywords []string
+}
+
+// userAgentRules are checked in order, browsers embedding another browser's
+// token (Edge and Opera contain Chrome, Chrome contains Safari) come first.
+var userAgentRules = []userAgentRule{
+	{family: "Bot", keywords: []string{"bot", "crawler", "spider", "slurp"}},
+	{family: "Edge", keywords: []string{"edg/", "edga/", "edgios/"}},
+	{family: "Opera", keywords: []string{"opr/", "opera"}},
+	{family: "Samsung Internet", keywords: []string{"samsungbrowser/"}},
+	{family: "Chrome", keywords: []string{"chrome/", "crios/", "chromium/"}},
+	{family: "Firefox", keywords: []string{"firefox/", "fxios/"}},
+	{family: "Safari", keywords: []string{"safari/"}},
+	{family: "curl", keywords: []string{"curl/"}},
+	{family: "Wget", keywords: []string{"wget/"}},
+	{family: "Postman", keywords: []string{"postmanruntime/"}},
+}
+
+func userAgentFamily(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown"
+	}
+
+	lowered := strings.ToLower(userAgent)
+	for _, rule := range userAgentRules {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(lowered, keyword) {
+				return rule.family
+			}
+		}
+	}
+	return "Other"
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/cache"
+	"github.com/Alturino/url-shortener/internal/config"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const defaultFlushInterval = 5 * time.Second
+
+type pendingVisit struct {
+	shortUrl string
+	delta    int64
+}
+
+// VisitCounter aggregates visits in memory and periodically flushes them as
+// relative increments, so replicas never overwrite each other's counts and the
+// redirect path never waits for postgres.
+type VisitCounter struct {
+	queries  *repository.Queries
+	cache    cache.UrlCache
+	interval time.Duration
+
+	mutex   sync.Mutex
+	pending map[uuid.UUID]*pendingVisit
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewVisitCounter(
+	queries *repository.Queries,
+	cache cache.UrlCache,
+	config config.VisitCounter,
+) *VisitCounter {
+	interval := config.FlushInterval
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	return &VisitCounter{
+		queries:  queries,
+		cache:    cache,
+		interval: interval,
+		pending:  map[uuid.UUID]*pendingVisit{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (v *VisitCounter) Count(url repository.Url) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.add(url.ID, url.ShortUrl, 1)
+}
+
+// Pending returns the visits of id that are not flushed yet.
+func (v *VisitCounter) Pending(id uuid.UUID) int64 {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if visit, ok := v.pending[id]; ok {
+		return visit.delta
+	}
+	return 0
+}
+
+// Start flushes the pending visits every interval until Shutdown is called.
+func (v *VisitCounter) Start(c context.Context) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "VisitCounter").Logger()
+	c = logger.WithContext(context.WithoutCancel(c))
+
+	go func() {
+		defer close(v.done)
+
+		ticker := time.NewTicker(v.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				v.Flush(c)
+			case <-v.stop:
+				logger.Info().Msg("flushing pending visits before shutdown")
+				v.Flush(c)
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the flush loop after a last flush of the pending visits.
+func (v *VisitCounter) Shutdown(c context.Context) error {
+	close(v.stop)
+	select {
+	case <-v.done:
+		return nil
+	case <-c.Done():
+		return fmt.Errorf("failed flushing pending visits with error=%w", c.Err())
+	}
+}
+
+func (v *VisitCounter) Flush(c context.Context) {
+	c, span := tracer.Start(c, "VisitCounter Flush")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	v.mutex.Lock()
+	pending := v.pending
+	v.pending = map[uuid.UUID]*pendingVisit{}
+	v.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(pending))
+	deltas := make([]int64, 0, len(pending))
+	for id, visit := range pending {
+		ids = append(ids, id)
+		deltas = append(deltas, visit.delta)
+	}
+
+	logger.Info().Msgf("flushing visited_count of %d urls", len(ids))
+	err := v.queries.IncrementVisitedCountUrls(
+		c,
+		repository.IncrementVisitedCountUrlsParams{Ids: ids, Deltas: deltas},
+	)
+	if err != nil {
+		err = fmt.Errorf("failed flushing visited_count with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+
+		v.mutex.Lock()
+		for id, visit := range pending {
+			v.add(id, visit.shortUrl, visit.delta)
+		}
+		v.mutex.Unlock()
+		return
+	}
+	logger.Info().Msgf("flushed visited_count of %d urls", len(ids))
+
+	for _, visit := range pending {
+		_, err := v.cache.IncrementVisitedCount(c, visit.shortUrl, visit.delta)
+		if err != nil && !errors.Is(err, cache.ErrCacheMiss) {
+			err = fmt.Errorf(
+				"failed incrementing visited_count for shortUrl=%s in cache with error=%w",
+				visit.shortUrl,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+		}
+	}
+}
+
+func (v *VisitCounter) add(id uuid.UUID, shortUrl string, delta int64) {
+	visit, ok := v.pending[id]
+	if !ok {
+		visit = &pendingVisit{shortUrl: shortUrl}
+		v.pending[id] = visit
+	}
+	visit.delta += delta
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/Alturino/url-shortener/internal/cache"
+	"github.com/Alturino/url-shortener/internal/config"
+	"github.com/Alturino/url-shortener/internal/log"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+const defaultRollupInterval = time.Hour
+
+type visitorDay struct {
+	urlID    uuid.UUID
+	shortUrl string
+	day      string
+}
+
+type DailyVisitors struct {
+	Day      string `json:"day"`
+	Visitors int64  `json:"visitors"`
+}
+
+// UniqueVisitorCounter estimates unique visitors per url and day with redis
+// HyperLogLogs and periodically rolls the estimates of the touched days up to
+// postgres, so they outlive the HyperLogLog keys. Without a cache no visitor
+// is tracked and only the rollups recorded before are reported.
+type UniqueVisitorCounter struct {
+	queries  *repository.Queries
+	cache    *cache.VisitorCache
+	interval time.Duration
+
+	mutex   sync.Mutex
+	touched map[visitorDay]struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewUniqueVisitorCounter(
+	queries *repository.Queries,
+	cache *cache.VisitorCache,
+	config config.Analytics,
+) *UniqueVisitorCounter {
+	interval := config.RollupInterval
+	if interval <= 0 {
+		interval = defaultRollupInterval
+	}
+	return &UniqueVisitorCounter{
+		queries:  queries,
+		cache:    cache,
+		interval: interval,
+		touched:  map[visitorDay]struct{}{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Track adds the visitors of clicks to the HyperLogLog of their day, a visitor
+// is identified by the anonymized client ip and the user agent so clicks
+// without a client ip are skipped.
+func (u *UniqueVisitorCounter) Track(c context.Context, clicks []Click) {
+	if u.cache == nil {
+		return
+	}
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	visitors := make([]cache.Visitor, 0, len(clicks))
+	days := make(map[visitorDay]struct{}, len(clicks))
+	for _, click := range clicks {
+		if click.ClientIP == "" {
+			continue
+		}
+		day := click.ClickedAt.UTC().Truncate(24 * time.Hour)
+		visitors = append(visitors, cache.Visitor{
+			UrlID: click.UrlID,
+			Day:   day,
+			ID:    visitorID(click.Visit),
+		})
+		days[visitorDay{
+			urlID:    click.UrlID,
+			shortUrl: click.ShortUrl,
+			day:      day.Format(cache.DayLayout),
+		}] = struct{}{}
+	}
+	if len(visitors) == 0 {
+		return
+	}
+
+	logger.Info().Msgf("tracking %d unique visitors", len(visitors))
+	err := u.cache.Add(c, visitors)
+	if err != nil {
+		err = fmt.Errorf("failed tracking %d unique visitors with error=%w", len(visitors), err)
+		logger.Error().Err(err).Msg(err.Error())
+		return
+	}
+	logger.Info().Msgf("tracked %d unique visitors", len(visitors))
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	for day := range days {
+		u.touched[day] = struct{}{}
+	}
+}
+
+// Start rolls the touched days up every interval until Shutdown is called.
+func (u *UniqueVisitorCounter) Start(c context.Context) {
+	logger := zerolog.Ctx(c).With().Str(log.KeyProcess, "UniqueVisitorCounter").Logger()
+	c = logger.WithContext(context.WithoutCancel(c))
+
+	go func() {
+		defer close(u.done)
+
+		ticker := time.NewTicker(u.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				u.Rollup(c)
+			case <-u.stop:
+				logger.Info().Msg("rolling up unique visitors before shutdown")
+				u.Rollup(c)
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the rollup loop after a last rollup of the touched days.
+func (u *UniqueVisitorCounter) Shutdown(c context.Context) error {
+	close(u.stop)
+	select {
+	case <-u.done:
+		return nil
+	case <-c.Done():
+		return fmt.Errorf("failed rolling up unique visitors with error=%w", c.Err())
+	}
+}
+
+func (u *UniqueVisitorCounter) Rollup(c context.Context) {
+	c, span := tracer.Start(c, "UniqueVisitorCounter Rollup")
+	defer span.End()
+
+	logger := zerolog.Ctx(c).With().Logger()
+
+	u.mutex.Lock()
+	touched := u.touched
+	u.touched = map[visitorDay]struct{}{}
+	u.mutex.Unlock()
+
+	if len(touched) == 0 {
+		return
+	}
+
+	param := repository.UpsertDailyUniqueVisitorsParams{
+		UrlIds:   make([]uuid.UUID, 0, len(touched)),
+		Days:     make([]string, 0, len(touched)),
+		Visitors: make([]int64, 0, len(touched)),
+	}
+	for visitor := range touched {
+		day, _ := time.Parse(cache.DayLayout, visitor.day)
+		counts, err := u.cache.Count(c, visitor.urlID, []time.Time{day})
+		if err != nil {
+			err = fmt.Errorf(
+				"failed counting unique visitors of shortUrl=%s day=%s with error=%w",
+				visitor.shortUrl,
+				visitor.day,
+				err,
+			)
+			logger.Error().Err(err).Msg(err.Error())
+			u.retouch(visitor)
+			continue
+		}
+		param.UrlIds = append(param.UrlIds, visitor.urlID)
+		param.Days = append(param.Days, visitor.day)
+		param.Visitors = append(param.Visitors, counts[0])
+	}
+	if len(param.UrlIds) == 0 {
+		return
+	}
+
+	logger.Info().Msgf("rolling up unique visitors of %d days", len(param.UrlIds))
+	err := u.queries.UpsertDailyUniqueVisitors(c, param)
+	if err != nil {
+		err = fmt.Errorf("failed rolling up unique visitors with error=%w", err)
+		logger.Error().Err(err).Msg(err.Error())
+		for visitor := range touched {
+			u.retouch(visitor)
+		}
+		return
+	}
+	logger.Info().Msgf("rolled up unique visitors of %d days", len(param.UrlIds))
+}
+
+// Daily returns the unique visitors of url per day between from and to, days
+// still covered by a HyperLogLog are answered from redis and the older ones
+// from the rollups.
+func (u *UniqueVisitorCounter) Daily(
+	c context.Context,
+	url repository.Url,
+	from time.Time,
+	to time.Time,
+) ([]DailyVisitors, error) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+
+	logger.Info().Msgf("finding daily unique visitors of shortUrl=%s", url.ShortUrl)
+	rows, err := u.queries.FindDailyUniqueVisitors(c, repository.FindDailyUniqueVisitorsParams{
+		UrlID:      url.ID,
+		StartedDay: from.Format(cache.DayLayout),
+		EndedDay:   to.Format(cache.DayLayout),
+	})
+	if err != nil {
+		err = fmt.Errorf(
+			"failed finding daily unique visitors of shortUrl=%s with error=%w",
+			url.ShortUrl,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+		return nil, err
+	}
+	visitors := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		visitors[row.Day.Format(cache.DayLayout)] = row.Visitors
+	}
+	logger.Info().Msgf("found daily unique visitors of shortUrl=%s", url.ShortUrl)
+
+	if u.cache != nil {
+		u.countLive(c, url, from, to, visitors)
+	}
+
+	daily := make([]DailyVisitors, 0, len(visitors))
+	for day, count := range visitors {
+		daily = append(daily, DailyVisitors{Day: day, Visitors: count})
+	}
+	sort.Slice(daily, func(i, j int) bool { return daily[i].Day < daily[j].Day })
+	return daily, nil
+}
+
+// countLive raises visitors to the estimates of the days between from and to
+// that are still covered by a HyperLogLog.
+func (u *UniqueVisitorCounter) countLive(
+	c context.Context,
+	url repository.Url,
+	from time.Time,
+	to time.Time,
+	visitors map[string]int64,
+) {
+	logger := zerolog.Ctx(c).With().Logger()
+
+	live := []time.Time{}
+	oldest := time.Now().UTC().Add(-u.cache.TTL()).Truncate(24 * time.Hour)
+	if from.After(oldest) {
+		oldest = from
+	}
+	for day := oldest; !day.After(to); day = day.Add(24 * time.Hour) {
+		live = append(live, day)
+	}
+
+	logger.Info().Msgf("counting live unique visitors of shortUrl=%s", url.ShortUrl)
+	counts, err := u.cache.Count(c, url.ID, live)
+	if err != nil {
+		err = fmt.Errorf(
+			"failed counting live unique visitors of shortUrl=%s with error=%w",
+			url.ShortUrl,
+			err,
+		)
+		logger.Error().Err(err).Msg(err.Error())
+	}
+	for i, count := range counts {
+		day := live[i].Format(cache.DayLayout)
+		if count > visitors[day] {
+			visitors[day] = count
+		}
+	}
+	logger.Info().Msgf("counted live unique visitors of shortUrl=%s", url.ShortUrl)
+}
+
+func (u *UniqueVisitorCounter) retouch(visitor visitorDay) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.touched[visitor] = struct{}{}
+}
+
+func visitorID(visit Visit) string {
+	sum := sha256.Sum256([]byte(visit.ClientIP + "|" + visit.UserAgent))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,252 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+// columns are the csv columns of an export, an import may order them freely
+// and leave out everything except url and short_url.
+var columns = []string{
+	"id",
+	"url",
+	"short_url",
+	"created_at",
+	"updated_at",
+	"visited_count",
+	"redirect_status",
+	"expires_at",
+	"max_clicks",
+	"active_from",
+	"active_until",
+	"owner_id",
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	header bool
+	record []string
+}
+
+func newCsvWriter(w io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w), record: make([]string, len(columns))}
+}
+
+func (c *csvWriter) Write(url repository.Url) error {
+	if !c.header {
+		err := c.writer.Write(columns)
+		if err != nil {
+			return err
+		}
+		c.header = true
+	}
+
+	c.record[0] = url.ID.String()
+	c.record[1] = url.Url
+	c.record[2] = url.ShortUrl
+	c.record[3] = formatTime(&url.CreatedAt)
+	c.record[4] = formatTime(&url.UpdatedAt)
+	c.record[5] = strconv.FormatInt(int64(url.VisitedCount), 10)
+	c.record[6] = strconv.FormatInt(int64(url.RedirectStatus), 10)
+	c.record[7] = formatTime(url.ExpiresAt)
+	c.record[8] = ""
+	if url.MaxClicks != nil {
+		c.record[8] = strconv.FormatInt(int64(*url.MaxClicks), 10)
+	}
+	c.record[9] = formatTime(url.ActiveFrom)
+	c.record[10] = formatTime(url.ActiveUntil)
+	c.record[11] = ""
+	if url.OwnerID.Valid {
+		c.record[11] = url.OwnerID.UUID.String()
+	}
+	return c.writer.Write(c.record)
+}
+
+// Flush writes the header even when nothing was exported so an empty export
+// is still a valid import.
+func (c *csvWriter) Flush() error {
+	if !c.header {
+		err := c.writer.Write(columns)
+		if err != nil {
+			return err
+		}
+		c.header = true
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	index  map[string]int
+	line   int
+}
+
+func newCsvReader(r io.Reader) *csvReader {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	return &csvReader{reader: reader}
+}
+
+func (c *csvReader) readHeader() error {
+	header, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("failed reading csv header with error=%w: %w", ErrInvalidRecord, err)
+	}
+
+	known := map[string]struct{}{"destination_host": {}}
+	for _, column := range columns {
+		known[column] = struct{}{}
+	}
+	c.index = make(map[string]int, len(header))
+	for i, column := range header {
+		if _, ok := known[column]; !ok {
+			return fmt.Errorf("unknown csv column=%s with error=%w", column, ErrInvalidRecord)
+		}
+		c.index[column] = i
+	}
+	for _, column := range []string{"url", "short_url"} {
+		if _, ok := c.index[column]; !ok {
+			return fmt.Errorf("missing csv column=%s with error=%w", column, ErrInvalidRecord)
+		}
+	}
+	return nil
+}
+
+// Read decodes the next record, empty fields of nullable columns are null and
+// any other empty field is left zero.
+func (c *csvReader) Read() (repository.Url, error) {
+	if c.index == nil {
+		err := c.readHeader()
+		if err != nil {
+			return repository.Url{}, err
+		}
+	}
+
+	record, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return repository.Url{}, io.EOF
+	}
+	c.line++
+	if err != nil {
+		return repository.Url{}, fmt.Errorf(
+			"failed reading csv record=%d with error=%w: %w",
+			c.line,
+			ErrInvalidRecord,
+			err,
+		)
+	}
+
+	url, err := c.decode(record)
+	if err != nil {
+		return repository.Url{}, fmt.Errorf(
+			"failed decoding csv record=%d with error=%w: %w",
+			c.line,
+			ErrInvalidRecord,
+			err,
+		)
+	}
+	return url, nil
+}
+
+func (c *csvReader) decode(record []string) (repository.Url, error) {
+	field := func(column string) string {
+		i, ok := c.index[column]
+		if !ok {
+			return ""
+		}
+		return record[i]
+	}
+
+	url := repository.Url{Url: field("url"), ShortUrl: field("short_url")}
+	var err error
+	if id := field("id"); id != "" {
+		url.ID, err = uuid.Parse(id)
+		if err != nil {
+			return url, fmt.Errorf("failed parsing id=%s: %w", id, err)
+		}
+	}
+	if ownerID := field("owner_id"); ownerID != "" {
+		url.OwnerID.UUID, err = uuid.Parse(ownerID)
+		if err != nil {
+			return url, fmt.Errorf("failed parsing owner_id=%s: %w", ownerID, err)
+		}
+		url.OwnerID.Valid = true
+	}
+
+	for column, dst := range map[string]*time.Time{
+		"created_at": &url.CreatedAt,
+		"updated_at": &url.UpdatedAt,
+	} {
+		parsed, err := parseTime(column, field(column))
+		if err != nil {
+			return url, err
+		}
+		if parsed != nil {
+			*dst = *parsed
+		}
+	}
+	for column, dst := range map[string]**time.Time{
+		"expires_at":   &url.ExpiresAt,
+		"active_from":  &url.ActiveFrom,
+		"active_until": &url.ActiveUntil,
+	} {
+		*dst, err = parseTime(column, field(column))
+		if err != nil {
+			return url, err
+		}
+	}
+
+	if visitedCount := field("visited_count"); visitedCount != "" {
+		parsed, err := strconv.ParseInt(visitedCount, 10, 32)
+		if err != nil {
+			return url, fmt.Errorf("failed parsing visited_count=%s: %w", visitedCount, err)
+		}
+		url.VisitedCount = int32(parsed)
+	}
+	if redirectStatus := field("redirect_status"); redirectStatus != "" {
+		parsed, err := strconv.ParseInt(redirectStatus, 10, 16)
+		if err != nil {
+			return url, fmt.Errorf("failed parsing redirect_status=%s: %w", redirectStatus, err)
+		}
+		url.RedirectStatus = int16(parsed)
+	}
+	if maxClicks := field("max_clicks"); maxClicks != "" {
+		parsed, err := strconv.ParseInt(maxClicks, 10, 32)
+		if err != nil {
+			return url, fmt.Errorf("failed parsing max_clicks=%s: %w", maxClicks, err)
+		}
+		clicks := int32(parsed)
+		url.MaxClicks = &clicks
+	}
+	return url, nil
+}
+
+func parseTime(column, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing %s=%s: %w", column, value, err)
+	}
+	return &parsed, nil
+}
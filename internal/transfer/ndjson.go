@@ -0,0 +1,61 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+type ndjsonWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newNdjsonWriter(w io.Writer) *ndjsonWriter {
+	buffer := bufio.NewWriter(w)
+	return &ndjsonWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+func (n *ndjsonWriter) Write(url repository.Url) error {
+	return n.encoder.Encode(url)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.buffer.Flush()
+}
+
+type ndjsonReader struct {
+	decoder *json.Decoder
+	line    int
+}
+
+func newNdjsonReader(r io.Reader) *ndjsonReader {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return &ndjsonReader{decoder: decoder}
+}
+
+// Read decodes the next url, the generated destination_host of an export is
+// accepted and dropped.
+func (n *ndjsonReader) Read() (repository.Url, error) {
+	n.line++
+	url := repository.Url{}
+	err := n.decoder.Decode(&url)
+	if errors.Is(err, io.EOF) {
+		return repository.Url{}, io.EOF
+	}
+	if err != nil {
+		return repository.Url{}, fmt.Errorf(
+			"failed decoding url at record=%d with error=%w: %w",
+			n.line,
+			ErrInvalidRecord,
+			err,
+		)
+	}
+	url.DestinationHost = nil
+	return url, nil
+}
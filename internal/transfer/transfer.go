@@ -0,0 +1,71 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
+	"github.com/Alturino/url-shortener/internal/repository"
+)
+
+type Format string
+
+const (
+	FormatCsv    Format = "csv"
+	FormatNdjson Format = "ndjson"
+)
+
+var (
+	ErrInvalidFormat = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_format",
+		"format must be csv or ndjson",
+	)
+	ErrInvalidRecord = apperrors.New(
+		apperrors.KindInvalid,
+		"invalid_record",
+		"invalid import record",
+	)
+)
+
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case FormatCsv, FormatNdjson:
+		return Format(format), nil
+	}
+	return "", fmt.Errorf("unknown format=%s with error=%w", format, ErrInvalidFormat)
+}
+
+// ContentType is the media type of an export in f.
+func (f Format) ContentType() string {
+	if f == FormatCsv {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// Writer encodes urls one at a time, Flush writes whatever is buffered to the
+// underlying writer.
+type Writer interface {
+	Write(url repository.Url) error
+	Flush() error
+}
+
+// Reader decodes urls one at a time, it returns io.EOF after the last url.
+type Reader interface {
+	Read() (repository.Url, error)
+}
+
+func NewWriter(format Format, w io.Writer) Writer {
+	if format == FormatCsv {
+		return newCsvWriter(w)
+	}
+	return newNdjsonWriter(w)
+}
+
+func NewReader(format Format, r io.Reader) Reader {
+	if format == FormatCsv {
+		return newCsvReader(r)
+	}
+	return newNdjsonReader(r)
+}
@@ -0,0 +1,249 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+
+	"github.com/Alturino/url-shortener/internal/config"
+	apperrors "github.com/Alturino/url-shortener/internal/errors"
+)
+
+const defaultMaxLength = 2048
+
+type Reason string
+
+const (
+	ReasonEmpty                 Reason = "empty"
+	ReasonTooLong               Reason = "too_long"
+	ReasonMalformed             Reason = "malformed"
+	ReasonNotAbsolute           Reason = "not_absolute"
+	ReasonSchemeNotAllowed      Reason = "scheme_not_allowed"
+	ReasonMissingHost           Reason = "missing_host"
+	ReasonInvalidHost           Reason = "invalid_host"
+	ReasonCredentialsNotAllowed Reason = "credentials_not_allowed"
+	ReasonOwnHost               Reason = "own_host"
+	ReasonPrivateAddress        Reason = "private_address"
+	// ReasonBlocked is reported for destinations blocked by the policy engine.
+	ReasonBlocked Reason = "blocked"
+)
+
+var ErrInvalidDestination = apperrors.New(
+	apperrors.KindInvalid,
+	"invalid_destination",
+	"invalid destination",
+)
+
+// DestinationError is the reason a destination url was rejected.
+type DestinationError struct {
+	Reason  Reason
+	Url     string
+	Message string
+}
+
+func (e *DestinationError) Error() string {
+	return fmt.Sprintf("url=%s %s reason=%s", e.Url, e.Message, e.Reason)
+}
+
+func (e *DestinationError) Unwrap() error {
+	return ErrInvalidDestination
+}
+
+func (e *DestinationError) Detail() string {
+	return "destination url " + e.Message
+}
+
+func (e *DestinationError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"reason": e.Reason}
+}
+
+// DestinationValidator checks that a destination url is safe to redirect to
+// and normalizes its scheme and host.
+type DestinationValidator struct {
+	allowedSchemes        map[string]struct{}
+	ownHosts              map[string]struct{}
+	maxLength             int
+	allowPrivateAddresses bool
+}
+
+func NewDestinationValidator(config config.Destination) *DestinationValidator {
+	allowedSchemes := map[string]struct{}{}
+	for _, scheme := range config.AllowedSchemes {
+		allowedSchemes[strings.ToLower(scheme)] = struct{}{}
+	}
+	if len(allowedSchemes) == 0 {
+		allowedSchemes = map[string]struct{}{"http": {}, "https": {}}
+	}
+
+	ownHosts := map[string]struct{}{}
+	for _, host := range config.OwnHosts {
+		normalized, err := idna.Lookup.ToASCII(strings.ToLower(host))
+		if err != nil {
+			normalized = strings.ToLower(host)
+		}
+		ownHosts[normalized] = struct{}{}
+	}
+
+	maxLength := config.MaxLength
+	if maxLength <= 0 {
+		maxLength = defaultMaxLength
+	}
+
+	return &DestinationValidator{
+		allowedSchemes:        allowedSchemes,
+		ownHosts:              ownHosts,
+		maxLength:             maxLength,
+		allowPrivateAddresses: config.AllowPrivateAddresses,
+	}
+}
+
+// Validate returns raw normalized with a lowercase scheme and a punycode host
+// or a *DestinationError wrapping ErrInvalidDestination.
+func (v *DestinationValidator) Validate(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, v.reject(ReasonEmpty, raw, "must not be empty")
+	}
+	if len(raw) > v.maxLength {
+		return nil, v.reject(
+			ReasonTooLong,
+			raw[:min(len(raw), 64)]+"...",
+			fmt.Sprintf("must not be longer than %d characters", v.maxLength),
+		)
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, v.reject(ReasonMalformed, raw, "is not a valid url")
+	}
+	if !parsed.IsAbs() {
+		return nil, v.reject(ReasonNotAbsolute, raw, "must be an absolute url with a scheme")
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if _, ok := v.allowedSchemes[parsed.Scheme]; !ok {
+		return nil, v.reject(
+			ReasonSchemeNotAllowed,
+			raw,
+			fmt.Sprintf("has scheme=%s which is not allowed", parsed.Scheme),
+		)
+	}
+	if parsed.User != nil {
+		return nil, v.reject(
+			ReasonCredentialsNotAllowed,
+			parsed.Redacted(),
+			"must not contain credentials",
+		)
+	}
+
+	hostname := strings.TrimSuffix(parsed.Hostname(), ".")
+	if hostname == "" {
+		return nil, v.reject(ReasonMissingHost, raw, "must have a host")
+	}
+
+	ip := parseIP(hostname)
+	if ip == nil {
+		hostname, err = idna.Lookup.ToASCII(hostname)
+		if err != nil {
+			return nil, v.reject(ReasonInvalidHost, raw, "has a host that is not a valid domain")
+		}
+	} else {
+		hostname = ip.String()
+	}
+	if port := parsed.Port(); port != "" {
+		parsed.Host = net.JoinHostPort(hostname, port)
+	} else if ip != nil && ip.To4() == nil {
+		parsed.Host = "[" + hostname + "]"
+	} else {
+		parsed.Host = hostname
+	}
+
+	if _, ok := v.ownHosts[hostname]; ok {
+		return nil, v.reject(ReasonOwnHost, raw, "must not point back at the shortener")
+	}
+	if !v.allowPrivateAddresses && (hostname == "localhost" || isPrivateIP(ip)) {
+		return nil, v.reject(
+			ReasonPrivateAddress,
+			raw,
+			"must not point at a private, loopback or link-local address",
+		)
+	}
+
+	return parsed, nil
+}
+
+func (v *DestinationValidator) reject(reason Reason, raw string, message string) error {
+	return &DestinationError{Reason: reason, Url: raw, Message: message}
+}
+
+// parseIP parses an ip literal including the inet_aton forms browsers resolve
+// as an ipv4 address, such as 2130706433, 127.1, 0177.0.0.1 or 0x7f.0.0.1.
+func parseIP(hostname string) net.IP {
+	if ip := net.ParseIP(hostname); ip != nil {
+		return ip
+	}
+
+	parts := strings.Split(hostname, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+	numbers := make([]uint64, len(parts))
+	for i, part := range parts {
+		number, ok := parseIPv4Part(part)
+		if !ok {
+			return nil
+		}
+		numbers[i] = number
+	}
+
+	// every part but the last is a single byte, the last fills the remaining
+	// bytes so 127.1 is 127.0.0.1 and 10.65535 is 10.0.255.255
+	last := numbers[len(numbers)-1]
+	if last >= 1<<(8*(5-len(numbers))) {
+		return nil
+	}
+	address := last
+	for i, number := range numbers[:len(numbers)-1] {
+		if number > 0xff {
+			return nil
+		}
+		address |= number << (8 * (3 - i))
+	}
+	return net.IPv4(byte(address>>24), byte(address>>16), byte(address>>8), byte(address))
+}
+
+// parseIPv4Part parses a part of an inet_aton address which is hexadecimal
+// with a 0x prefix, octal with a leading 0 and decimal otherwise.
+func parseIPv4Part(part string) (uint64, bool) {
+	base := 10
+	digits := part
+	switch {
+	case len(part) > 2 && (part[:2] == "0x" || part[:2] == "0X"):
+		base, digits = 16, part[2:]
+	case len(part) > 1 && part[0] == '0':
+		base, digits = 8, part[1:]
+	}
+	if digits == "" || strings.ContainsAny(digits, "+-_") {
+		return 0, false
+	}
+	number, err := strconv.ParseUint(digits, base, 32)
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+func isPrivateIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	return ip.IsPrivate() ||
+		ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified()
+}
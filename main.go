@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"net"
 	"net/http"
@@ -11,16 +10,21 @@ import (
 	"syscall"
 	"time"
 
+	goredis "github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
+	"github.com/Alturino/url-shortener/internal/auth"
 	"github.com/Alturino/url-shortener/internal/cache"
 	"github.com/Alturino/url-shortener/internal/config"
 	"github.com/Alturino/url-shortener/internal/controller"
 	"github.com/Alturino/url-shortener/internal/database"
+	"github.com/Alturino/url-shortener/internal/generator"
 	"github.com/Alturino/url-shortener/internal/log"
 	"github.com/Alturino/url-shortener/internal/middleware"
+	"github.com/Alturino/url-shortener/internal/policy"
 	"github.com/Alturino/url-shortener/internal/repository"
 	"github.com/Alturino/url-shortener/internal/service"
+	"github.com/Alturino/url-shortener/internal/validation"
 )
 
 func main() {
@@ -84,35 +88,284 @@ func main() {
 		Any(log.KeyConfig, appConfig).
 		Msg("initialized postgresql client")
 
+	// redis is only connected for the features that use it, the url cache
+	// backend cannot run without it while rate limiting and unique visitors
+	// degrade until it is reachable
+	redisRequired := cache.UsesRedis(appConfig.Cache.Backend)
+	var redis *goredis.Client
+	if redisRequired || appConfig.RateLimit.Enabled || appConfig.Analytics.UniqueVisitors {
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("initializing redis client")
+		redis = cache.NewCacheClient(c, appConfig.Cache)
+		err = cache.Ping(c, redis)
+		if err != nil && redisRequired {
+			logger.Fatal().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed initializing redis client with error=%s", err.Error())
+		}
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed pinging redis, degrading features using it with error=%s", err)
+		}
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("initialized redis client")
+	}
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msgf("initializing urlCache backend=%s", appConfig.Cache.Backend)
+	urlCache, err := cache.NewUrlCache(c, appConfig.Cache, redis)
+	if err != nil {
+		logger.Fatal().
+			Err(err).
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msgf("failed initializing urlCache with error=%s", err.Error())
+	}
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msgf("initialized urlCache backend=%s", appConfig.Cache.Backend)
+
+	queries := repository.New(db)
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msgf("initializing shortCodeGenerator=%s", appConfig.ShortCode.Generator)
+	shortCodeGenerator, err := generator.NewShortCodeGenerator(appConfig.ShortCode, queries)
+	if err != nil {
+		logger.Fatal().
+			Err(err).
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msgf("failed initializing shortCodeGenerator with error=%s", err.Error())
+	}
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msgf("initialized shortCodeGenerator=%s", appConfig.ShortCode.Generator)
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initializing visitCounter")
+	visitCounter := service.NewVisitCounter(queries, urlCache, appConfig.VisitCounter)
+	visitCounter.Start(c)
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initialized visitCounter")
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initializing uniqueVisitorCounter")
+	var visitorCache *cache.VisitorCache
+	if appConfig.Analytics.UniqueVisitors {
+		visitorCache = cache.NewVisitorCache(redis, appConfig.Analytics.VisitorTTL)
+	}
+	uniqueVisitorCounter := service.NewUniqueVisitorCounter(
+		queries,
+		visitorCache,
+		appConfig.Analytics,
+	)
+	uniqueVisitorCounter.Start(c)
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initialized uniqueVisitorCounter")
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initializing clickRecorder")
+	clickRecorder := service.NewClickRecorder(queries, uniqueVisitorCounter, appConfig.Analytics)
+	clickRecorder.Start(c)
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initialized clickRecorder")
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msgf("initializing urlReaper mode=%s", appConfig.Expiration.ReaperMode)
+	urlReaper, err := service.NewUrlReaper(db, queries, urlCache, appConfig.Expiration)
+	if err != nil {
+		logger.Fatal().
+			Err(err).
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msgf("failed initializing urlReaper with error=%s", err.Error())
+	}
+	urlReaper.Start(c)
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msgf("initialized urlReaper mode=%s", appConfig.Expiration.ReaperMode)
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msgf("initializing urlPurger quarantine=%s", appConfig.Deletion.Quarantine)
+	urlPurger := service.NewUrlPurger(db, queries, urlCache, appConfig.Deletion)
+	urlPurger.Start(c)
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msgf("initialized urlPurger quarantine=%s", appConfig.Deletion.Quarantine)
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initializing apiKeyService")
+	apiKeyService := service.NewApiKeyService(queries)
+	if appConfig.Auth.BootstrapAdminKey != "" {
+		err = apiKeyService.Bootstrap(c, appConfig.Auth.BootstrapAdminKey)
+		if err != nil {
+			logger.Fatal().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Msgf("failed bootstrapping admin api key with error=%s", err.Error())
+		}
+	}
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initialized apiKeyService")
+
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initializing auditService")
+	auditService := service.NewAuditService(queries)
 	logger.Info().
 		Str(log.KeyProcess, "main").
 		Any(log.KeyConfig, appConfig).
-		Msg("initializing redis client")
-	redis := cache.NewCacheClient(c, appConfig.Cache)
+		Msg("initialized auditService")
+
+	var jwtVerifier *auth.JWTVerifier
+	if appConfig.Auth.JWT.Enabled {
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msgf("initializing jwtVerifier jwksSource=%s", appConfig.Auth.JWT.JWKSSource)
+		jwks := auth.NewJWKS(appConfig.Auth.JWT.JWKSSource)
+		err = jwks.Load(c)
+		if err != nil {
+			logger.Fatal().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed loading jwks with error=%s", err.Error())
+		}
+		if appConfig.Auth.JWT.JWKSRefreshInterval > 0 {
+			go jwks.Refresh(c, appConfig.Auth.JWT.JWKSRefreshInterval)
+		}
+		jwtVerifier = auth.NewJWTVerifier(jwks, appConfig.Auth.JWT)
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msgf("initialized jwtVerifier jwksSource=%s", appConfig.Auth.JWT.JWKSSource)
+	}
+	authenticator := auth.NewAuthenticator(apiKeyService, jwtVerifier)
+
 	logger.Info().
 		Str(log.KeyProcess, "main").
 		Any(log.KeyConfig, appConfig).
-		Msg("initialized redis client")
+		Msg("initializing destinationValidator")
+	destinationValidator := validation.NewDestinationValidator(appConfig.Destination)
+	logger.Info().
+		Str(log.KeyProcess, "main").
+		Any(log.KeyConfig, appConfig).
+		Msg("initialized destinationValidator")
+
+	var policyEngine *policy.Engine
+	if appConfig.Policy.Enabled {
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msgf("initializing policyEngine rulesFile=%s", appConfig.Policy.RulesFile)
+		policyEngine = policy.NewEngine(appConfig.Policy)
+		err = policyEngine.Load(c)
+		if err != nil {
+			logger.Fatal().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed loading policy rules with error=%s", err.Error())
+		}
+		policyEngine.Start(c)
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msgf("initialized policyEngine rulesFile=%s", appConfig.Policy.RulesFile)
+	}
 
 	logger.Info().
 		Str(log.KeyProcess, "main").
 		Any(log.KeyConfig, appConfig).
 		Msg("initializing urlService")
-	queries := repository.New(db)
-	encoder := base64.StdEncoding
-	urlService := service.NewUrlService(redis, db, encoder, queries)
+	urlService := service.NewUrlService(
+		urlCache,
+		db,
+		shortCodeGenerator,
+		queries,
+		visitCounter,
+		clickRecorder,
+		uniqueVisitorCounter,
+		destinationValidator,
+		policyEngine,
+		appConfig.Policy,
+		appConfig.Batch,
+		appConfig.ShortCode,
+	)
 	logger.Info().
 		Str(log.KeyProcess, "main").
 		Any(log.KeyConfig, appConfig).
 		Msg("initialized urlService")
 
 	mux := http.NewServeMux()
-	middlewares := middleware.CreateStack(middleware.Logging, middleware.Otlp)
+	stack := []middleware.Middleware{
+		middleware.Logging,
+		middleware.Otlp,
+		middleware.Authenticate(authenticator),
+	}
+	if appConfig.RateLimit.Enabled {
+		rateLimiter := cache.NewRateLimiter(redis)
+		stack = append(stack, middleware.RateLimit(rateLimiter, appConfig.RateLimit))
+	}
+	if appConfig.Application.TrustProxyHeaders {
+		realIP := middleware.RealIP(appConfig.Application.TrustedProxyHops)
+		stack = append([]middleware.Middleware{realIP}, stack...)
+	}
+	middlewares := middleware.CreateStack(stack...)
 	otelhttpHandler := otelhttp.NewHandler(
 		middlewares(mux),
 		"url-shortener",
 	)
-	controller.AttachUrlController(mux, urlService)
+	controller.AttachUrlController(
+		mux,
+		urlService,
+		appConfig.Expiration,
+		appConfig.Activation,
+		appConfig.Policy,
+		appConfig.Batch,
+	)
+	controller.AttachApiKeyController(mux, apiKeyService)
+	controller.AttachTransferController(mux, urlService)
+	controller.AttachAuditController(mux, auditService)
 
 	server := http.Server{
 		Addr:         fmt.Sprintf("%s:%d", appConfig.Application.Host, appConfig.Application.Port),
@@ -143,7 +396,9 @@ func main() {
 			Str(log.KeyProcess, "main").
 			Any(log.KeyConfig, appConfig).
 			Msg("shutting down server")
-		err := server.Shutdown(c)
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(c), 10*time.Second)
+		defer cancel()
+		err := server.Shutdown(shutdownCtx)
 		if err != nil {
 			logger.Fatal().
 				Err(err).
@@ -156,5 +411,109 @@ func main() {
 			Str(log.KeyProcess, "main").
 			Any(log.KeyConfig, appConfig).
 			Msg("shutdown server")
+
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutting down visitCounter")
+		err = visitCounter.Shutdown(shutdownCtx)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed shutting down visitCounter with error=%s", err.Error())
+		}
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutdown visitCounter")
+
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutting down clickRecorder")
+		err = clickRecorder.Shutdown(shutdownCtx)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed shutting down clickRecorder with error=%s", err.Error())
+		}
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutdown clickRecorder")
+
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutting down uniqueVisitorCounter")
+		err = uniqueVisitorCounter.Shutdown(shutdownCtx)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed shutting down uniqueVisitorCounter with error=%s", err.Error())
+		}
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutdown uniqueVisitorCounter")
+
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutting down urlReaper")
+		err = urlReaper.Shutdown(shutdownCtx)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed shutting down urlReaper with error=%s", err.Error())
+		}
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutdown urlReaper")
+
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutting down urlPurger")
+		err = urlPurger.Shutdown(shutdownCtx)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msgf("failed shutting down urlPurger with error=%s", err.Error())
+		}
+		logger.Info().
+			Str(log.KeyProcess, "main").
+			Any(log.KeyConfig, appConfig).
+			Msg("shutdown urlPurger")
+
+		if policyEngine != nil {
+			logger.Info().
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msg("shutting down policyEngine")
+			err = policyEngine.Shutdown(shutdownCtx)
+			if err != nil {
+				logger.Error().
+					Err(err).
+					Str(log.KeyProcess, "main").
+					Any(log.KeyConfig, appConfig).
+					Msgf("failed shutting down policyEngine with error=%s", err.Error())
+			}
+			logger.Info().
+				Str(log.KeyProcess, "main").
+				Any(log.KeyConfig, appConfig).
+				Msg("shutdown policyEngine")
+		}
 	}
 }
@@ -0,0 +1 @@
+create sequence if not exists short_url_seq start with 14776336;
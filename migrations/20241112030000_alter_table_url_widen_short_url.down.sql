@@ -0,0 +1 @@
+alter table urls alter column short_url type varchar(7) using left(short_url, 7);
@@ -0,0 +1 @@
+alter table urls alter column short_url type varchar(32);
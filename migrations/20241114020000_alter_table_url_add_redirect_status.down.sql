@@ -0,0 +1,2 @@
+alter table urls drop constraint if exists urls_redirect_status_check;
+alter table urls drop column if exists redirect_status;
@@ -0,0 +1,2 @@
+alter table urls add column if not exists redirect_status smallint not null default (302);
+alter table urls add constraint urls_redirect_status_check check (redirect_status in (301, 302, 307, 308));
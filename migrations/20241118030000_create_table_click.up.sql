@@ -0,0 +1,11 @@
+create table if not exists clicks (
+    id bigserial primary key not null,
+    url_id uuid not null references urls (id) on delete cascade,
+    clicked_at timestamp not null default (now()),
+    referrer text not null default (''),
+    user_agent text not null default (''),
+    client_ip text not null default (''),
+    accept_language text not null default ('')
+);
+
+create index if not exists idx_clicks_url_id_clicked_at on clicks (url_id, clicked_at);
@@ -0,0 +1 @@
+alter table clicks drop column if exists user_agent_family;
@@ -0,0 +1 @@
+alter table clicks add column if not exists user_agent_family text not null default ('');
@@ -0,0 +1,7 @@
+create table if not exists daily_unique_visitors (
+    url_id uuid not null references urls (id) on delete cascade,
+    day date not null,
+    visitors bigint not null default (0),
+    updated_at timestamp not null default (now()),
+    primary key (url_id, day)
+);
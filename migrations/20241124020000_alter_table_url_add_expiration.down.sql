@@ -0,0 +1,5 @@
+drop table if exists archived_urls;
+drop index if exists idx_urls_expires_at;
+alter table urls drop constraint if exists urls_max_clicks_check;
+alter table urls drop column if exists max_clicks;
+alter table urls drop column if exists expires_at;
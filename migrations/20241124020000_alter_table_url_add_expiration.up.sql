@@ -0,0 +1,20 @@
+alter table urls add column if not exists expires_at timestamptz;
+alter table urls add column if not exists max_clicks int;
+alter table urls add constraint urls_max_clicks_check check (max_clicks > 0);
+
+create index if not exists idx_urls_expires_at on urls (expires_at) where expires_at is not null;
+
+create table if not exists archived_urls (
+    id uuid primary key not null,
+    url text not null,
+    short_url varchar(32) not null,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    visited_count int not null,
+    redirect_status smallint not null,
+    expires_at timestamptz,
+    max_clicks int,
+    archived_at timestamp not null default (now())
+);
+
+create index if not exists idx_archived_urls_short_url on archived_urls (short_url);
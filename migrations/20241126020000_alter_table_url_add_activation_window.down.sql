@@ -0,0 +1,6 @@
+alter table archived_urls drop column if exists active_until;
+alter table archived_urls drop column if exists active_from;
+
+alter table urls drop constraint if exists urls_activation_window_check;
+alter table urls drop column if exists active_until;
+alter table urls drop column if exists active_from;
@@ -0,0 +1,6 @@
+alter table urls add column if not exists active_from timestamptz;
+alter table urls add column if not exists active_until timestamptz;
+alter table urls add constraint urls_activation_window_check check (active_from < active_until);
+
+alter table archived_urls add column if not exists active_from timestamptz;
+alter table archived_urls add column if not exists active_until timestamptz;
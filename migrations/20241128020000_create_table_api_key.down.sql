@@ -0,0 +1,6 @@
+alter table archived_urls drop column if exists owner_id;
+
+drop index if exists idx_urls_owner_id;
+alter table urls drop column if exists owner_id;
+
+drop table if exists api_keys;
@@ -0,0 +1,16 @@
+create table if not exists api_keys (
+    id uuid primary key not null default (gen_random_uuid()),
+    owner_id uuid not null,
+    name text not null default (''),
+    key_hash text unique not null,
+    is_admin boolean not null default (false),
+    created_at timestamp not null default (now()),
+    revoked_at timestamptz
+);
+
+create index if not exists idx_api_keys_owner_id on api_keys (owner_id);
+
+alter table urls add column if not exists owner_id uuid;
+create index if not exists idx_urls_owner_id on urls (owner_id);
+
+alter table archived_urls add column if not exists owner_id uuid;
@@ -0,0 +1,4 @@
+alter table api_keys drop constraint if exists api_keys_role_check;
+alter table api_keys add column if not exists is_admin boolean not null default (false);
+update api_keys set is_admin = (role = 'admin');
+alter table api_keys drop column if exists role;
@@ -0,0 +1,4 @@
+alter table api_keys add column if not exists role text not null default ('editor');
+update api_keys set role = 'admin' where is_admin;
+alter table api_keys drop column if exists is_admin;
+alter table api_keys add constraint api_keys_role_check check (role in ('admin', 'editor', 'viewer'));
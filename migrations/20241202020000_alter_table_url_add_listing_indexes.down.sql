@@ -0,0 +1,9 @@
+drop index if exists idx_urls_short_url_trgm;
+drop index if exists idx_urls_url_trgm;
+drop index if exists idx_urls_destination_host;
+drop index if exists idx_urls_updated_at;
+drop index if exists idx_urls_owner_id_created_at_id;
+drop index if exists idx_urls_visited_count_id;
+drop index if exists idx_urls_created_at_id;
+
+alter table urls drop column if exists destination_host;
@@ -0,0 +1,13 @@
+create extension if not exists pg_trgm;
+
+alter table urls add column if not exists destination_host text generated always as (
+    lower(substring(url from '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^/?#@]*@)?(\[[^]/?#]*\]|[^/?#:]*)'))
+) stored;
+
+create index if not exists idx_urls_created_at_id on urls (created_at desc, id desc);
+create index if not exists idx_urls_visited_count_id on urls (visited_count desc, id desc);
+create index if not exists idx_urls_owner_id_created_at_id on urls (owner_id, created_at desc, id desc);
+create index if not exists idx_urls_updated_at on urls (updated_at);
+create index if not exists idx_urls_destination_host on urls (destination_host);
+create index if not exists idx_urls_url_trgm on urls using gin (url gin_trgm_ops);
+create index if not exists idx_urls_short_url_trgm on urls using gin (short_url gin_trgm_ops);
@@ -0,0 +1,3 @@
+drop index if exists idx_urls_deleted_at;
+
+alter table urls drop column if exists deleted_at;
@@ -0,0 +1,3 @@
+alter table urls add column if not exists deleted_at timestamptz;
+
+create index if not exists idx_urls_deleted_at on urls (deleted_at) where deleted_at is not null;
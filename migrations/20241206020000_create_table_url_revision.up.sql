@@ -0,0 +1,12 @@
+create table if not exists url_revisions (
+    id bigserial primary key not null,
+    url_id uuid not null references urls (id) on delete cascade,
+    revision integer not null,
+    url text not null,
+    action text not null,
+    source_revision integer,
+    actor_id uuid,
+    actor_role text not null default (''),
+    created_at timestamptz not null default (now()),
+    unique (url_id, revision)
+);
@@ -0,0 +1,2 @@
+drop table if exists audit_logs;
+drop function if exists reject_audit_log_change;
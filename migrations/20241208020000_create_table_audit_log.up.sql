@@ -0,0 +1,33 @@
+create table if not exists audit_logs (
+    id bigserial primary key not null,
+    actor_id uuid,
+    actor_role text not null default (''),
+    action text not null,
+    short_url text not null default (''),
+    before jsonb not null default ('null'),
+    after jsonb not null default ('null'),
+    client_ip text not null default (''),
+    hashcode text not null default (''),
+    created_at timestamptz not null default (now())
+);
+
+create index if not exists idx_audit_logs_created_at on audit_logs (created_at);
+create index if not exists idx_audit_logs_short_url_id on audit_logs (short_url, id desc);
+create index if not exists idx_audit_logs_actor_id_id on audit_logs (actor_id, id desc);
+create index if not exists idx_audit_logs_hashcode on audit_logs (hashcode);
+
+create or replace function reject_audit_log_change() returns trigger as $$
+begin
+    raise exception 'audit_logs is append only';
+end;
+$$ language plpgsql;
+
+drop trigger if exists audit_logs_append_only on audit_logs;
+create trigger audit_logs_append_only
+before update or delete on audit_logs
+for each row execute function reject_audit_log_change();
+
+drop trigger if exists audit_logs_no_truncate on audit_logs;
+create trigger audit_logs_no_truncate
+before truncate on audit_logs
+for each statement execute function reject_audit_log_change();
@@ -0,0 +1,14 @@
+-- name: InsertApiKey :one
+insert into api_keys(id, owner_id, name, key_hash, role)
+values($1, $2, $3, $4, $5) returning *;
+
+-- name: InsertApiKeyIfNotExists :exec
+insert into api_keys(id, owner_id, name, key_hash, role)
+values($1, $2, $3, $4, $5)
+on conflict (key_hash) do nothing;
+
+-- name: FindApiKeyByKeyHash :one
+select * from api_keys where key_hash = $1 and revoked_at is null;
+
+-- name: RevokeApiKey :one
+update api_keys set revoked_at = now() where id = $1 and revoked_at is null returning *;
@@ -0,0 +1,22 @@
+-- name: InsertAuditLog :exec
+insert into audit_logs (actor_id, actor_role, action, short_url, before, after, client_ip, hashcode)
+values ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListAuditLogs :many
+select * from audit_logs
+where
+    (sqlc.narg('actor_id')::uuid is null or actor_id = sqlc.narg('actor_id')::uuid)
+    and (sqlc.narg('action')::text is null or action = sqlc.narg('action')::text)
+    and (sqlc.narg('short_url')::text is null or short_url = sqlc.narg('short_url')::text)
+    and (sqlc.narg('hashcode')::text is null or hashcode = sqlc.narg('hashcode')::text)
+    and (
+        sqlc.narg('created_from')::timestamptz is null
+        or created_at >= sqlc.narg('created_from')::timestamptz
+    )
+    and (
+        sqlc.narg('created_to')::timestamptz is null
+        or created_at < sqlc.narg('created_to')::timestamptz
+    )
+    and (sqlc.narg('cursor_id')::bigint is null or id < sqlc.narg('cursor_id')::bigint)
+order by id desc
+limit @row_limit;
@@ -0,0 +1,77 @@
+-- name: InsertClicks :exec
+insert into clicks (
+    url_id, clicked_at, referrer, user_agent, user_agent_family, client_ip, accept_language
+)
+select
+    clicked.url_id,
+    clicked.clicked_at,
+    clicked.referrer,
+    clicked.user_agent,
+    clicked.user_agent_family,
+    clicked.client_ip,
+    clicked.accept_language
+from (
+    select
+        unnest(@url_ids::uuid[]) as url_id,
+        unnest(@clicked_ats::text[])::timestamptz as clicked_at,
+        unnest(@referrers::text[]) as referrer,
+        unnest(@user_agents::text[]) as user_agent,
+        unnest(@user_agent_families::text[]) as user_agent_family,
+        unnest(@client_ips::text[]) as client_ip,
+        unnest(@accept_languages::text[]) as accept_language
+) as clicked
+where exists (select 1 from urls where urls.id = clicked.url_id);
+
+-- name: CountClicksByInterval :many
+select
+    buckets.bucket::timestamptz as bucket,
+    count(clicks.id) as clicks
+from generate_series(
+    date_trunc(@interval::text, (@started_at::timestamptz)::timestamp),
+    (@ended_at::timestamptz)::timestamp,
+    ('1 ' || @interval::text)::interval
+) as buckets (bucket)
+left join clicks
+    on
+        clicks.url_id = @url_id
+        and date_trunc(@interval::text, clicks.clicked_at) = buckets.bucket
+        and clicks.clicked_at >= @started_at::timestamptz
+        and clicks.clicked_at < @ended_at::timestamptz
+group by buckets.bucket
+order by buckets.bucket;
+
+-- name: CountClicks :one
+select
+    count(*) as clicks,
+    count(distinct nullif(client_ip, '')) as unique_visitors
+from clicks
+where
+    url_id = @url_id
+    and clicked_at >= @started_at::timestamptz
+    and clicked_at < @ended_at::timestamptz;
+
+-- name: FindTopReferrers :many
+select
+    referrer,
+    count(*) as clicks
+from clicks
+where
+    url_id = @url_id
+    and clicked_at >= @started_at::timestamptz
+    and clicked_at < @ended_at::timestamptz
+group by referrer
+order by clicks desc, referrer asc
+limit @top_limit;
+
+-- name: FindTopUserAgentFamilies :many
+select
+    user_agent_family,
+    count(*) as clicks
+from clicks
+where
+    url_id = @url_id
+    and clicked_at >= @started_at::timestamptz
+    and clicked_at < @ended_at::timestamptz
+group by user_agent_family
+order by clicks desc, user_agent_family asc
+limit @top_limit;
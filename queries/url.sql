@@ -1,14 +1,287 @@
 -- name: InsertUrl :one
-insert into urls(id, url, short_url) values($1, $2, $3) returning *;
+insert into urls(
+    id, url, short_url, redirect_status, expires_at, max_clicks, active_from, active_until, owner_id
+)
+values($1, $2, $3, $4, $5, $6, $7, $8, $9) returning *;
 
 -- name: UpdateUrl :one
-update urls set url = $2 where short_url = $1 returning *;
+update urls
+set
+    url = $2,
+    redirect_status = coalesce(sqlc.narg('redirect_status'), redirect_status),
+    expires_at = case
+        when @clear_expires_at::boolean then null
+        else coalesce(sqlc.narg('expires_at'), expires_at)
+    end,
+    max_clicks = case
+        when @clear_max_clicks::boolean then null
+        else coalesce(sqlc.narg('max_clicks'), max_clicks)
+    end,
+    active_from = case
+        when @clear_active_from::boolean then null
+        else coalesce(sqlc.narg('active_from'), active_from)
+    end,
+    active_until = case
+        when @clear_active_until::boolean then null
+        else coalesce(sqlc.narg('active_until'), active_until)
+    end,
+    updated_at = now()
+where short_url = $1 and deleted_at is null returning *;
 
--- name: UpdateVisitedCountUrl :one
-update urls set visited_count = $2 where id = $1 returning *;
+-- name: IncrementVisitedCountUrls :exec
+update urls
+set visited_count = urls.visited_count + visits.delta
+from (
+    select
+        unnest(@ids::uuid[]) as id,
+        unnest(@deltas::bigint[]) as delta
+) as visits
+where urls.id = visits.id;
 
 -- name: FindUrlByShortUrl :one
 select * from urls where short_url = $1;
 
--- name: DeleteUrlByShortUrl :one
-delete from urls where short_url = $1 returning *;
+-- name: FindUrlByShortUrlForUpdate :one
+select * from urls where short_url = $1 for update;
+
+-- name: UpdateUrlDestination :one
+update urls set url = $2, updated_at = now() where id = $1 returning *;
+
+-- name: SoftDeleteUrlByShortUrl :one
+update urls set deleted_at = now()
+where short_url = $1 and deleted_at is null
+returning *;
+
+-- name: RestoreUrlByShortUrl :one
+update urls set deleted_at = null, updated_at = now()
+where short_url = $1 and deleted_at is not null
+returning *;
+
+-- name: PurgeDeletedUrls :many
+delete from urls
+where id in (
+    select deleted.id from urls as deleted
+    where deleted.deleted_at <= @deleted_before::timestamptz
+    limit @batch_size
+    for update skip locked
+)
+returning *;
+
+-- name: NextShortUrlSequence :one
+select nextval('short_url_seq')::bigint;
+
+-- name: SoftDeleteExpiredUrls :many
+update urls set deleted_at = now()
+where id in (
+    select expired.id from urls as expired
+    where
+        expired.deleted_at is null
+        and (
+            (expired.expires_at is not null and expired.expires_at <= now())
+            or (expired.max_clicks is not null and expired.visited_count >= expired.max_clicks)
+        )
+    limit @batch_size
+    for update skip locked
+)
+returning *;
+
+-- name: ArchiveExpiredUrls :many
+with archived as (
+    insert into archived_urls (
+        id,
+        url,
+        short_url,
+        created_at,
+        updated_at,
+        visited_count,
+        redirect_status,
+        expires_at,
+        max_clicks,
+        active_from,
+        active_until,
+        owner_id
+    )
+    select
+        candidate.id,
+        candidate.url,
+        candidate.short_url,
+        candidate.created_at,
+        candidate.updated_at,
+        candidate.visited_count,
+        candidate.redirect_status,
+        candidate.expires_at,
+        candidate.max_clicks,
+        candidate.active_from,
+        candidate.active_until,
+        candidate.owner_id
+    from urls as candidate
+    where
+        candidate.deleted_at is null
+        and (
+            (candidate.expires_at is not null and candidate.expires_at <= now())
+            or (
+                candidate.max_clicks is not null
+                and candidate.visited_count >= candidate.max_clicks
+            )
+        )
+    limit @batch_size
+    for update skip locked
+    on conflict (id) do update set
+        url = excluded.url,
+        updated_at = excluded.updated_at,
+        visited_count = excluded.visited_count,
+        redirect_status = excluded.redirect_status,
+        expires_at = excluded.expires_at,
+        max_clicks = excluded.max_clicks,
+        active_from = excluded.active_from,
+        active_until = excluded.active_until,
+        archived_at = now()
+    returning id
+)
+update urls set deleted_at = now()
+where id in (select id from archived)
+returning *;
+
+-- name: ListUrlsByCreatedAt :many
+select * from urls
+where
+    deleted_at is null
+    and (sqlc.narg('owner_id')::uuid is null or owner_id = sqlc.narg('owner_id')::uuid)
+    and (
+        sqlc.narg('created_from')::timestamptz is null
+        or created_at >= sqlc.narg('created_from')::timestamptz
+    )
+    and (
+        sqlc.narg('created_to')::timestamptz is null
+        or created_at < sqlc.narg('created_to')::timestamptz
+    )
+    and (
+        sqlc.narg('updated_from')::timestamptz is null
+        or updated_at >= sqlc.narg('updated_from')::timestamptz
+    )
+    and (
+        sqlc.narg('updated_to')::timestamptz is null
+        or updated_at < sqlc.narg('updated_to')::timestamptz
+    )
+    and (sqlc.narg('host')::text is null or destination_host = sqlc.narg('host')::text)
+    and (
+        sqlc.narg('search')::text is null
+        or url ilike sqlc.narg('search')::text
+        or short_url ilike sqlc.narg('search')::text
+    )
+    and (
+        sqlc.narg('cursor_created_at')::timestamp is null
+        or (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, @cursor_id::uuid)
+    )
+order by created_at desc, id desc
+limit @row_limit;
+
+-- name: ListUrlsByVisitedCount :many
+select * from urls
+where
+    deleted_at is null
+    and (sqlc.narg('owner_id')::uuid is null or owner_id = sqlc.narg('owner_id')::uuid)
+    and (
+        sqlc.narg('created_from')::timestamptz is null
+        or created_at >= sqlc.narg('created_from')::timestamptz
+    )
+    and (
+        sqlc.narg('created_to')::timestamptz is null
+        or created_at < sqlc.narg('created_to')::timestamptz
+    )
+    and (
+        sqlc.narg('updated_from')::timestamptz is null
+        or updated_at >= sqlc.narg('updated_from')::timestamptz
+    )
+    and (
+        sqlc.narg('updated_to')::timestamptz is null
+        or updated_at < sqlc.narg('updated_to')::timestamptz
+    )
+    and (sqlc.narg('host')::text is null or destination_host = sqlc.narg('host')::text)
+    and (
+        sqlc.narg('search')::text is null
+        or url ilike sqlc.narg('search')::text
+        or short_url ilike sqlc.narg('search')::text
+    )
+    and (
+        sqlc.narg('cursor_visited_count')::int is null
+        or (visited_count, id) < (sqlc.narg('cursor_visited_count')::int, @cursor_id::uuid)
+    )
+order by visited_count desc, id desc
+limit @row_limit;
+
+-- name: ExportUrls :many
+select * from urls
+where id > @after_id and deleted_at is null
+order by id
+limit @batch_size;
+
+-- name: FindUrlsByShortUrls :many
+select * from urls where short_url = any(@short_urls::text[]);
+
+-- name: ImportUrl :one
+insert into urls (
+    id,
+    url,
+    short_url,
+    created_at,
+    updated_at,
+    visited_count,
+    redirect_status,
+    expires_at,
+    max_clicks,
+    active_from,
+    active_until,
+    owner_id
+)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+returning *;
+
+-- name: ImportUrlIfNotExists :one
+insert into urls (
+    id,
+    url,
+    short_url,
+    created_at,
+    updated_at,
+    visited_count,
+    redirect_status,
+    expires_at,
+    max_clicks,
+    active_from,
+    active_until,
+    owner_id
+)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+on conflict do nothing
+returning *;
+
+-- name: ImportUrlOverwrite :one
+insert into urls (
+    id,
+    url,
+    short_url,
+    created_at,
+    updated_at,
+    visited_count,
+    redirect_status,
+    expires_at,
+    max_clicks,
+    active_from,
+    active_until,
+    owner_id
+)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+on conflict (short_url) do update
+set
+    url = excluded.url,
+    created_at = excluded.created_at,
+    updated_at = excluded.updated_at,
+    visited_count = excluded.visited_count,
+    redirect_status = excluded.redirect_status,
+    expires_at = excluded.expires_at,
+    max_clicks = excluded.max_clicks,
+    active_from = excluded.active_from,
+    active_until = excluded.active_until,
+    owner_id = excluded.owner_id
+returning *, (xmax = 0)::boolean as inserted;
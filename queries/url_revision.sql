@@ -0,0 +1,30 @@
+-- name: InsertBaselineUrlRevision :exec
+insert into url_revisions (url_id, revision, url, action, actor_id, created_at)
+select
+    @url_id::uuid,
+    1,
+    @url::text,
+    'create',
+    sqlc.narg('owner_id')::uuid,
+    @created_at::timestamptz
+where not exists (select 1 from url_revisions where url_id = @url_id::uuid);
+
+-- name: InsertUrlRevision :one
+insert into url_revisions (url_id, revision, url, action, source_revision, actor_id, actor_role)
+select
+    @url_id::uuid,
+    coalesce(max(revision), 0) + 1,
+    @url::text,
+    @action::text,
+    sqlc.narg('source_revision')::integer,
+    sqlc.narg('actor_id')::uuid,
+    @actor_role::text
+from url_revisions
+where url_id = @url_id::uuid
+returning *;
+
+-- name: ListUrlRevisions :many
+select * from url_revisions where url_id = $1 order by revision desc;
+
+-- name: FindUrlRevision :one
+select * from url_revisions where url_id = $1 and revision = $2;
@@ -0,0 +1,28 @@
+-- name: UpsertDailyUniqueVisitors :exec
+insert into daily_unique_visitors (url_id, day, visitors)
+select
+    rolled.url_id,
+    rolled.day,
+    rolled.visitors
+from (
+    select
+        unnest(@url_ids::uuid[]) as url_id,
+        unnest(@days::text[])::date as day,
+        unnest(@visitors::bigint[]) as visitors
+) as rolled
+where exists (select 1 from urls where urls.id = rolled.url_id)
+on conflict (url_id, day) do update
+    set
+        visitors = greatest(daily_unique_visitors.visitors, excluded.visitors),
+        updated_at = now();
+
+-- name: FindDailyUniqueVisitors :many
+select
+    day,
+    visitors
+from daily_unique_visitors
+where
+    url_id = @url_id
+    and day >= (@started_day::text)::date
+    and day <= (@ended_day::text)::date
+order by day;